@@ -0,0 +1,26 @@
+package main
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/b-b3rn4rd/json2ssm/pkg/storage"
+)
+
+const (
+	backendSSM            = "ssm"
+	backendSecretsManager = "secretsmanager"
+	backendFile           = "file"
+)
+
+func newStorage(sess *session.Session, cfg *aws.Config) storage.Storage {
+	switch *backend {
+	case backendSecretsManager:
+		return storage.NewSecretsManager(secretsmanager.New(sess, cfg), logger)
+	case backendFile:
+		return storage.NewFile(*backendPath)
+	}
+
+	return storage.New(ssm.New(sess, cfg), logger)
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/b-b3rn4rd/json2ssm/pkg/source"
+	"github.com/b-b3rn4rd/json2ssm/pkg/storage"
+)
+
+const ssmScheme = "ssm:"
+
+func loadSource(spec string, o *awsOptions) (map[string]interface{}, map[string]bool, error) {
+	if strings.HasPrefix(spec, ssmScheme) {
+		sess, cfg := newSession(o)
+		strg := storage.New(ssm.New(sess, cfg), logger)
+		return strg.Flatten(strings.TrimPrefix(spec, ssmScheme), true)
+	}
+
+	r, err := source.Open(spec, int64(*maxSize))
+	if err != nil {
+		return nil, nil, err
+	}
+	defer r.Close()
+
+	j := source.JSON{}
+	values, err := j.Flatten(r)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return values, map[string]bool{}, nil
+}
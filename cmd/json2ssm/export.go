@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/b-b3rn4rd/json2ssm/pkg/storage"
+)
+
+func exportFiltered() bool {
+	return !*getRecurse || len(*getInclude) > 0 || len(*getExclude) > 0 || len(*getFilters) > 0
+}
+
+func exportMetadata() bool {
+	return *getWithMeta || *getMetaOnly
+}
+
+// exportJSON exports the get-json path, export filters and metadata are only
+// supported by the ssm backend.
+func exportJSON(strg *storage.SSMStorage, store storage.Storage) (interface{}, error) {
+	keyed := getKeys.storage(store, "")
+	if !exportFiltered() && !exportMetadata() {
+		return keyed.Export(*getPath, *getDecrypt)
+	}
+
+	if *backend != backendSSM {
+		return nil, fmt.Errorf("export filters and metadata are only supported by the ssm backend")
+	}
+
+	filters, err := parameterFilters(*getFilters)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := storage.ExportOptions{
+		Decrypt:      *getDecrypt,
+		NonRecursive: !*getRecurse,
+		Include:      *getInclude,
+		Exclude:      *getExclude,
+		Filters:      filters,
+	}
+
+	var tree interface{}
+	if exportMetadata() {
+		tree, err = strg.ExportMetadata(context.Background(), *getPath, opts, !*getMetaOnly)
+	} else {
+		tree, err = strg.ExportContext(context.Background(), *getPath, opts)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if k, ok := keyed.(*storage.KeyedStorage); ok {
+		return k.Unformat(tree)
+	}
+
+	return tree, nil
+}
+
+// parameterFilters parses Key=Value[,Value] filters, keys are Type, KeyId,
+// Label and tag:<key>, the only ones GetParametersByPath accepts.
+func parameterFilters(specs []string) ([]*ssm.ParameterStringFilter, error) {
+	var filters []*ssm.ParameterStringFilter
+
+	for _, spec := range specs {
+		parts := strings.SplitN(spec, "=", 2)
+		key := parts[0]
+
+		switch {
+		case key == "Type", key == "KeyId", key == "Label":
+		case strings.HasPrefix(key, "tag:") && len(key) > len("tag:"):
+		default:
+			return nil, fmt.Errorf("unsupported filter %s, use Type, KeyId, Label or tag:<key>", spec)
+		}
+
+		f := &ssm.ParameterStringFilter{Key: aws.String(key)}
+		if len(parts) == 2 {
+			f.Values = aws.StringSlice(strings.Split(parts[1], ","))
+		} else if !strings.HasPrefix(key, "tag:") {
+			return nil, fmt.Errorf("filter %s needs a value, e.g. %s=value", spec, key)
+		}
+
+		filters = append(filters, f)
+	}
+
+	return filters, nil
+}
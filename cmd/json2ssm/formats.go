@@ -0,0 +1,163 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/alecthomas/kingpin"
+	"github.com/b-b3rn4rd/json2ssm/pkg/source"
+	"github.com/b-b3rn4rd/json2ssm/pkg/storage"
+)
+
+const (
+	formatJSON       = "json"
+	formatDotenv     = "dotenv"
+	formatINI        = "ini"
+	formatProperties = "properties"
+	formatTOML       = "toml"
+	formatHCL        = "hcl"
+)
+
+type sourceOptions struct {
+	format     *string
+	separator  *string
+	inferTypes *bool
+}
+
+func sourceFlags(flag func(string, string) *kingpin.FlagClause) *sourceOptions {
+	return &sourceOptions{
+		format:     flag("format", "The format of source files.").Default(formatJSON).Enum(formatJSON, formatDotenv, formatINI, formatProperties, formatTOML, formatHCL),
+		separator:  flag("separator", "The separator splitting keys into the parameter hierarchy, defaults to __ for dotenv and . for ini and properties.").String(),
+		inferTypes: flag("infer-types", "Convert true, false and numbers in dotenv, ini and properties files to booleans and numbers").Default("false").Bool(),
+	}
+}
+
+func (o *sourceOptions) flattener() source.Flattener {
+	separator := func(d string) string {
+		if *o.separator == "" {
+			return d
+		}
+
+		return *o.separator
+	}
+
+	switch *o.format {
+	case formatDotenv:
+		return &source.Dotenv{Separator: separator("__"), InferTypes: *o.inferTypes}
+	case formatINI:
+		return &source.INI{Separator: separator("."), InferTypes: *o.inferTypes}
+	case formatProperties:
+		return &source.Properties{Separator: separator("."), InferTypes: *o.inferTypes}
+	case formatTOML:
+		return &source.TOML{}
+	case formatHCL:
+		return &source.HCL{}
+	}
+
+	return &source.JSON{}
+}
+
+// flattenFiles flattens documents in order, later values override earlier
+// ones with the same key.
+func flattenFiles(paths []string, f source.Flattener) (map[string]interface{}, error) {
+	values := map[string]interface{}{}
+
+	for _, path := range paths {
+		r, err := source.Open(path, int64(*maxSize))
+		if err != nil {
+			return nil, err
+		}
+
+		flat, err := f.Flatten(r)
+		r.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		for k, v := range flat {
+			values[k] = v
+		}
+	}
+
+	return values, nil
+}
+
+type keyOptions struct {
+	separator *string
+	keyCase   *string
+	prefix    *string
+	suffix    *string
+	index     *string
+}
+
+func keyFlags(flag func(string, string) *kingpin.FlagClause) *keyOptions {
+	return &keyOptions{
+		separator: flag("key-separator", "The separator joining keys of nested objects into parameter names.").Default("/").String(),
+		keyCase:   flag("key-case", "Convert camelCase keys to kebab-case or lowercase them in parameter names, kebab rejects keys that can't be converted back, e.g. HTTPPort.").Default("keep").Enum("keep", source.CaseKebab, source.CaseLower),
+		prefix:    flag("key-prefix", "The prefix added to parameter names.").String(),
+		suffix:    flag("key-suffix", "The suffix added to parameter names.").String(),
+		index:     flag("index-format", "Name array items hosts/0 (plain) or hosts[0] (brackets).").Default(source.IndexPlain).Enum(source.IndexPlain, source.IndexBrackets),
+	}
+}
+
+// storage names parameters with the key format below root, the storage is
+// returned as is when every flag has its default.
+func (o *keyOptions) storage(store storage.Storage, root string) storage.Storage {
+	if o.isDefault() {
+		return store
+	}
+
+	return storage.NewKeyed(store, o.format(), root)
+}
+
+// check rejects key formats whose names the backend can't store, SSM
+// parameter and Secrets Manager secret names can't contain brackets.
+func (o *keyOptions) check(backend string) error {
+	if *o.index == source.IndexBrackets && backend != backendFile {
+		return fmt.Errorf("--index-format %s isn't supported by the %s backend, its names can't contain brackets", source.IndexBrackets, backend)
+	}
+
+	return nil
+}
+
+func (o *keyOptions) isDefault() bool {
+	return *o.format() == source.KeyFormat{Separator: "/", Index: source.IndexPlain}
+}
+
+func (o *keyOptions) format() *source.KeyFormat {
+	keyCase := *o.keyCase
+	if keyCase == "keep" {
+		keyCase = source.CaseKeep
+	}
+
+	return &source.KeyFormat{
+		Separator: *o.separator,
+		Case:      keyCase,
+		Prefix:    *o.prefix,
+		Suffix:    *o.suffix,
+		Index:     *o.index,
+	}
+}
+
+type filterOptions struct {
+	sel     *string
+	exclude *[]string
+	path    *string
+}
+
+func filterFlags(flag func(string, string) *kingpin.FlagClause) *filterOptions {
+	return &filterOptions{
+		sel:     flag("select", "The JSON pointer of the subtree that is kept, e.g. /services/payments.").String(),
+		exclude: flag("exclude", "Drop keys relative to the selected subtree matching the glob, e.g. */password, repeat for more globs.").Strings(),
+		path:    flag("path", "SSM parameter store path (prefix) the selected subtree is moved under.").String(),
+	}
+}
+
+func (o *filterOptions) isDefault() bool {
+	return *o.sel == "" && len(*o.exclude) == 0 && *o.path == ""
+}
+
+func (o *filterOptions) apply(values map[string]interface{}) (map[string]interface{}, error) {
+	f := &source.Filter{Select: *o.sel, Exclude: *o.exclude, Path: *o.path}
+
+	return f.Apply(values)
+}
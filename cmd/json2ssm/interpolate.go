@@ -0,0 +1,33 @@
+package main
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/aws/aws-sdk-go/service/ssm/ssmiface"
+)
+
+// refResolver returns decrypted parameter values for ${ref:/name}
+// placeholders, every parameter is fetched once.
+func refResolver(svc ssmiface.SSMAPI) func(string) (string, error) {
+	cache := map[string]string{}
+
+	return func(name string) (string, error) {
+		if v, ok := cache[name]; ok {
+			return v, nil
+		}
+
+		logger.WithField("name", name).Debug("resolving reference")
+
+		resp, err := svc.GetParameter(&ssm.GetParameterInput{
+			Name:           aws.String(name),
+			WithDecryption: aws.Bool(true),
+		})
+		if err != nil {
+			return "", err
+		}
+
+		cache[name] = aws.StringValue(resp.Parameter.Value)
+
+		return cache[name], nil
+	}
+}
@@ -1,14 +1,25 @@
 package main
 
 import (
+	"bytes"
+	"context"
 	"os"
 
 	"encoding/json"
 	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/alecthomas/kingpin"
-	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/b-b3rn4rd/json2ssm/pkg/atomicfile"
+	"github.com/b-b3rn4rd/json2ssm/pkg/backup"
+	"github.com/b-b3rn4rd/json2ssm/pkg/diff"
+	"github.com/b-b3rn4rd/json2ssm/pkg/env"
+	"github.com/b-b3rn4rd/json2ssm/pkg/render"
+	"github.com/b-b3rn4rd/json2ssm/pkg/server"
 	"github.com/b-b3rn4rd/json2ssm/pkg/source"
 	"github.com/b-b3rn4rd/json2ssm/pkg/storage"
 	"github.com/sirupsen/logrus"
@@ -18,14 +29,86 @@ var (
 	putJSON     = kingpin.Command("put-json", "Creates SSM parameters from the specified JSON file.")
 	getJSON     = kingpin.Command("get-json", "Retrieves JSON document from SSM parameter store using given path (prefix).")
 	delJSON     = kingpin.Command("del-json", "Deletes parameters from SSM parameter store based on the specified JSON file.")
+	move        = kingpin.Command("move", "Moves parameters with their metadata from one SSM parameter store path (prefix) to another.")
+	backupCmd   = kingpin.Command("backup", "Saves parameters with their metadata from SSM parameter store path (prefix) into a local archive.")
+	restoreCmd  = kingpin.Command("restore", "Restores parameters with their metadata from a local archive into SSM parameter store.")
+	execCmd     = kingpin.Command("exec", "Runs a command with parameters from SSM parameter store path (prefix) as environment variables.")
+	renderCmd   = kingpin.Command("render", "Renders a Go template with parameters from SSM parameter store path (prefix).")
+	diffCmd     = kingpin.Command("diff", "Compares two sources, each is either an SSM parameter store path prefixed with ssm: or a JSON file.")
+	lintCmd     = kingpin.Command("lint", "Checks keys and values of the specified JSON file against SSM naming, size and quota limits.")
+	validateCmd = kingpin.Command("validate", "Validates a source, either an SSM parameter store path prefixed with ssm: or a JSON file, against a JSON schema.")
+	serveCmd    = kingpin.Command("serve", "Serves parameters as JSON documents over HTTP under /v1/tree/{path}.")
 	getPath     = getJSON.Flag("path", "SSM parameter store path (prefix)").Required().String()
 	getDecrypt  = getJSON.Flag("decrypt", "Decrypt secure strings").Default("false").Bool()
-	putJSONFile = putJSON.Flag("json-file", "The path where your JSON file is located.").Required().ExistingFile()
+	getWatch    = getJSON.Flag("watch", "Keep polling the path and re-export when parameters under it get a new version, tag changes and references outside the path are not noticed").Default("false").Bool()
+	getInterval = getJSON.Flag("interval", "Polling interval used with --watch").Default("30s").Duration()
+	getOut      = getJSON.Flag("out", "The path where the JSON document is written instead of stdout.").String()
+	getOnChange = getJSON.Flag("on-change", "The shell command executed when the document changes, used with --watch.").String()
+	getResolve  = getJSON.Flag("resolve-refs", "Replace {{ssm:/name}} references with values of the referenced parameters").Default("false").Bool()
+	getRefDepth = getJSON.Flag("max-ref-depth", "The maximum number of references followed in a chain, used with --resolve-refs.").Default("5").Int()
+	getSchema   = getJSON.Flag("schema", "The path of the JSON schema the exported document is validated against.").ExistingFile()
+	getKeys     = keyFlags(getJSON.Flag)
+	getRecurse  = getJSON.Flag("recursive", "Export parameters at every level under the path, --no-recursive exports only the first level").Default("true").Bool()
+	getInclude  = getJSON.Flag("include", "Keep only keys relative to the path matching the glob, e.g. db/*, repeat for more globs.").Strings()
+	getExclude  = getJSON.Flag("exclude", "Drop keys relative to the path matching the glob, e.g. */password, repeat for more globs.").Strings()
+	getWithMeta = getJSON.Flag("with-metadata", "Export every parameter as {value, type, version, lastModifiedDate, lastModifiedUser, description, tier, keyId, labels}").Default("false").Bool()
+	getMetaOnly = getJSON.Flag("metadata-only", "Export the metadata of every parameter without its value").Default("false").Bool()
+	getFilters  = getJSON.Flag("filter", "Parameter filter passed to GetParametersByPath, e.g. Type=String,StringList, Label=prod or tag:team=core, repeat for more filters.").Strings()
+	putJSONFile = putJSON.Flag("json-file", "The path or http(s):// URL of your JSON file, --json-file=- reads stdin, repeat to merge files in order.").Required().Strings()
+	putInterp   = putJSON.Flag("interpolate", "Expand ${ENV}, ${ENV:-default} and ${ref:/ssm/param} placeholders in string values, $$ is a literal $").Default("false").Bool()
+	putMerged   = putJSON.Flag("print-merged", "Print the merged document instead of writing parameters").Default("false").Bool()
 	putJSONMsg  = putJSON.Flag("message", "The additional message used as parameters description.").Short('m').Default("").String()
 	putEncrypt  = putJSON.Flag("encrypt", "Encrypt all values with Secure String").Default("false").Bool()
-	delJSONFile = delJSON.Flag("json-file", "The path where your JSON file is located.").Required().ExistingFile()
+	putSchema   = putJSON.Flag("schema", "The path of the JSON schema the document is validated against before any write.").ExistingFile()
+	putSource   = sourceFlags(putJSON.Flag)
+	putKeys     = keyFlags(putJSON.Flag)
+	putFilter   = filterFlags(putJSON.Flag)
+	putStream   = putJSON.Flag("stream", "Write parameters while a single JSON file is read, memory doesn't grow with the file size").Default("false").Bool()
+	delJSONFile = delJSON.Flag("json-file", "The path or http(s):// URL of your JSON file, --json-file=- reads stdin.").Required().String()
+	delSource   = sourceFlags(delJSON.Flag)
+	delKeys     = keyFlags(delJSON.Flag)
+	delFilter   = filterFlags(delJSON.Flag)
+	moveFrom    = move.Flag("from", "SSM parameter store path (prefix) to move parameters from").Required().String()
+	moveTo      = move.Flag("to", "SSM parameter store path (prefix) to move parameters to").Required().String()
+	moveDestAWS = awsFlags(move.Flag, "dest-", "destination, defaults to the global flag")
+	backupPath  = backupCmd.Flag("path", "SSM parameter store path (prefix)").Required().String()
+	backupOut   = backupCmd.Flag("out", "The path where the archive is written.").Required().String()
+	backupPass  = backupCmd.Flag("passphrase", "Encrypt secure string values in the archive with the passphrase.").Envar("JSON2SSM_PASSPHRASE").String()
+	restoreIn   = restoreCmd.Flag("in", "The path where the archive is located.").Required().ExistingFile()
+	restoreTo   = restoreCmd.Flag("to", "SSM parameter store path (prefix) to restore parameters to instead of the original one").String()
+	restorePass = restoreCmd.Flag("passphrase", "The passphrase used to encrypt secure string values.").Envar("JSON2SSM_PASSPHRASE").String()
+	execPath    = execCmd.Flag("path", "SSM parameter store path (prefix)").Required().String()
+	execPrefix  = execCmd.Flag("prefix", "The prefix added to environment variable names.").Default("").String()
+	execCase    = execCmd.Flag("case", "Letter case of environment variable names.").Default(env.CaseUpper).Enum(env.CaseUpper, env.CaseLower, env.CaseKeep)
+	execKeep    = execCmd.Flag("keep-env", "Keep existing environment variables instead of overwriting them").Default("false").Bool()
+	execArgs    = execCmd.Arg("command", "The command with its arguments, e.g. -- ./server --port 8080.").Required().Strings()
+	renderPath  = renderCmd.Flag("path", "SSM parameter store path (prefix)").Required().String()
+	renderTmpl  = renderCmd.Flag("template", "The path where your template file is located.").Required().ExistingFile()
+	renderOut   = renderCmd.Flag("out", "The path where the rendered file is written.").Required().String()
+	renderMode  = renderCmd.Flag("mode", "File mode of the rendered file.").Default("0644").String()
+	renderDecr  = renderCmd.Flag("decrypt", "Decrypt secure strings").Default("false").Bool()
+	diffLeft    = diffCmd.Arg("left", "The source to compare from, e.g. ssm:/dev/myapp or config.json.").Required().String()
+	diffRight   = diffCmd.Arg("right", "The source to compare to, e.g. ssm:/prod/myapp or config.json.").Required().String()
+	diffLAWS    = awsFlags(diffCmd.Flag, "left-", "left SSM source")
+	diffRAWS    = awsFlags(diffCmd.Flag, "right-", "right SSM source")
+	diffSecrets = diffCmd.Flag("show-secrets", "Show secure string values instead of masking them").Default("false").Bool()
+	diffExit    = diffCmd.Flag("exit-code", "Exit with status 1 when sources differ").Default("false").Bool()
+	lintJSON    = lintCmd.Flag("json-file", "The path or http(s):// URL of your JSON file, --json-file=- reads stdin.").Required().String()
+	lintSource  = sourceFlags(lintCmd.Flag)
+	validateSch = validateCmd.Flag("schema", "The path of the JSON schema.").Required().ExistingFile()
+	validateSrc = validateCmd.Arg("source", "The source to validate, e.g. ssm:/prod/myapp or config.json.").Required().String()
+	serveListen = serveCmd.Flag("listen", "The address the server listens on.").Default("127.0.0.1:8080").String()
+	serveTTL    = serveCmd.Flag("cache-ttl", "How long exported documents are cached, 0 disables caching.").Default("5s").Duration()
+	serveToken  = serveCmd.Flag("token", "Require the bearer token in the Authorization header.").Envar("JSON2SSM_TOKEN").String()
+	serveRO     = serveCmd.Flag("read-only", "Reject PUT and DELETE requests").Default("false").Bool()
+	serveUnsafe = serveCmd.Flag("insecure", "Allow decrypted reads and writes without --token.").Default("false").Bool()
+	serveWrite  = serveCmd.Flag("write-timeout", "How long a response may take, exports of large paths are paced and can take minutes.").Default("10m").Duration()
 	version     = "master"
 	debug       = kingpin.Flag("debug", "Enable debug logging.").Short('d').Bool()
+	awsGlobal   = awsFlags(kingpin.Flag, "", "")
+	backend     = kingpin.Flag("backend", "Storage backend used by put-json, get-json, del-json and serve.").Default(backendSSM).Enum(backendSSM, backendSecretsManager, backendFile)
+	maxSize     = kingpin.Flag("max-size", "The maximum size of a JSON document read from a file, stdin, URL or a serve PUT request.").Default("10MB").Bytes()
+	backendPath = kingpin.Flag("backend-file", "The path of the JSON file used by the file backend.").Default("parameters.json").String()
 	logger      = logrus.New()
 	writer      = os.Stdout
 )
@@ -34,6 +117,12 @@ func main() {
 	kingpin.Version(version)
 	cmd := kingpin.Parse()
 
+	for _, keys := range []*keyOptions{getKeys, putKeys, delKeys} {
+		if err := keys.check(*backend); err != nil {
+			kingpin.Fatalf("%s", err)
+		}
+	}
+
 	if *debug {
 		logrus.SetLevel(logrus.DebugLevel)
 		logger.SetLevel(logrus.DebugLevel)
@@ -41,28 +130,29 @@ func main() {
 
 	logger.Formatter = &logrus.JSONFormatter{}
 
-	sess := session.Must(session.NewSessionWithOptions(session.Options{
-		SharedConfigState: session.SharedConfigEnable,
-	}))
-
-	strg := storage.New(ssm.New(sess), logger)
+	sess, cfg := newSession(awsGlobal)
+	strg := storage.New(ssm.New(sess, cfg), logger)
+	store := newStorage(sess, cfg)
 
 	switch cmd {
 
 	case "del-json":
-		j := source.JSON{}
-		r, err := os.Open(*delJSONFile)
+		r, err := source.Open(*delJSONFile, int64(*maxSize))
 		if err != nil {
 			logrus.WithError(err).Fatal("error while opening file")
 		}
 		defer r.Close()
 
-		body, err := j.Flatten(r)
+		body, err := delSource.flattener().Flatten(r)
 		if err != nil {
 			logrus.WithError(err).Fatal("error while flattering")
 		}
 
-		total, err := strg.Delete(body)
+		if body, err = delFilter.apply(body); err != nil {
+			logrus.WithError(err).Fatal("error while filtering")
+		}
+
+		total, err := delKeys.storage(store, *delFilter.path).Delete(body)
 		if err != nil {
 			logger.WithError(err).Fatal("error while deleting")
 		}
@@ -70,31 +160,336 @@ func main() {
 		fmt.Fprintf(writer, "\nDeletion has successfully finished, %d parameters have been removed from SSM parameter store. \n", total)
 
 	case "get-json":
-		values, err := strg.Export(*getPath, *getDecrypt)
+		if *getResolve && *backend != backendSSM {
+			logrus.Fatal("resolving references is only supported by the ssm backend")
+		}
+
+		if exportMetadata() && (*getResolve || *getSchema != "" || *getWatch) {
+			logrus.Fatal("metadata can't be combined with --resolve-refs, --schema or --watch")
+		}
+
+		if *getWatch {
+			if *backend != backendSSM {
+				logrus.Fatal("watch is only supported by the ssm backend")
+			}
+
+			if exportFiltered() {
+				logrus.Fatal("export filters can't be combined with --watch")
+			}
+
+			if err := watchJSON(strg); err != nil {
+				logrus.WithError(err).Fatal("error while watching")
+			}
+			return
+		}
+
+		values, err := exportJSON(strg, store)
 		if err != nil {
 			logrus.WithError(err).Fatal("error while exporting")
 		}
+
+		if *getResolve {
+			values, err = strg.ResolveRefs(context.Background(), values, storage.ResolveOptions{Decrypt: *getDecrypt, MaxDepth: *getRefDepth})
+			if err != nil {
+				logrus.WithError(err).Fatal("error while resolving references")
+			}
+		}
+
+		if *getSchema != "" {
+			total, err := validateTree(os.Stderr, *getSchema, *getPath, values)
+			if err != nil {
+				logrus.WithError(err).Fatal("error while validating")
+			}
+			if total > 0 {
+				logrus.Fatalf("document has %d schema violations", total)
+			}
+		}
+
 		raw, _ := json.MarshalIndent(values, "", " ")
+
+		if *getOut != "" {
+			if err = atomicfile.Write(*getOut, raw, 0644); err != nil {
+				logrus.WithError(err).Fatal("error while writing")
+			}
+			return
+		}
+
 		fmt.Fprint(writer, string(raw))
 
 	case "put-json":
-		j := source.JSON{}
-		r, err := os.Open(*putJSONFile)
+		if *putStream {
+			total, err := streamJSON(strg)
+			if err != nil {
+				logrus.WithError(err).Fatal("error while importing")
+			}
+
+			fmt.Fprintf(writer, "\nImport has successfully finished, %d parameters have been (over)written to SSM parameter store. \n", total)
+			return
+		}
+
+		var body map[string]interface{}
+		var doc interface{}
+		var err error
+
+		if *putSource.format == formatJSON {
+			doc, err = mergeFiles(*putJSONFile)
+		} else {
+			if *putSchema != "" {
+				logrus.Fatal("schema validation is only supported for json files")
+			}
+
+			body, err = flattenFiles(*putJSONFile, putSource.flattener())
+			doc = body
+		}
+		if err != nil {
+			logrus.WithError(err).Fatal("error while reading files")
+		}
+
+		if *putInterp {
+			in := &source.Interpolator{Ref: refResolver(ssm.New(sess, cfg))}
+			if doc, err = in.Interpolate(doc); err != nil {
+				logrus.WithError(err).Fatal("error while interpolating")
+			}
+		}
+
+		raw, err := json.MarshalIndent(doc, "", " ")
+		if err != nil {
+			logrus.WithError(err).Fatal("error while merging files")
+		}
+
+		if *putMerged {
+			fmt.Fprintln(writer, string(raw))
+			return
+		}
+
+		if *putSource.format != formatJSON {
+			body = doc.(map[string]interface{})
+		} else {
+			if *putSchema != "" {
+				total, err := validateTree(os.Stderr, *putSchema, "/", doc)
+				if err != nil {
+					logrus.WithError(err).Fatal("error while validating")
+				}
+				if total > 0 {
+					logrus.Fatalf("document has %d schema violations, nothing has been written", total)
+				}
+			}
+
+			j := source.JSON{}
+			body, err = j.Flatten(bytes.NewReader(raw))
+			if err != nil {
+				logrus.WithError(err).Fatal("error while flattering")
+			}
+		}
+
+		if body, err = putFilter.apply(body); err != nil {
+			logrus.WithError(err).Fatal("error while filtering")
+		}
+
+		total, err := putKeys.storage(store, *putFilter.path).Import(body, *putJSONMsg, *putEncrypt)
+		if err != nil {
+			logrus.WithError(err).Fatal("error while importing")
+		}
+
+		fmt.Fprintf(writer, "\nImport has successfully finished, %d parameters have been (over)written to SSM parameter store. \n", total)
+
+	case "move":
+		dest := strg
+		distinct := false
+		if !moveDestAWS.empty() {
+			destSess, destCfg := newSession(moveDestAWS.or(awsGlobal))
+			dest = storage.New(ssm.New(destSess, destCfg), logger)
+			distinct = distinctLocations(sess, cfg, destSess, destCfg)
+		}
+
+		total, err := strg.MoveTo(dest, *moveFrom, *moveTo, distinct)
+		if err != nil {
+			logrus.WithError(err).Fatal("error while moving")
+		}
+
+		fmt.Fprintf(writer, "\nMove has successfully finished, %d parameters have been moved from %s to %s. \n", total, *moveFrom, *moveTo)
+
+	case "backup":
+		params, err := strg.Snapshot(*backupPath)
+		if err != nil {
+			logrus.WithError(err).Fatal("error while reading parameters")
+		}
+
+		if *backupPass == "" {
+			logrus.Warn("passphrase is not specified, secure string values are stored unencrypted")
+		}
+
+		var buf bytes.Buffer
+		if err := backup.Write(&buf, *backupPath, params, *backupPass); err != nil {
+			logrus.WithError(err).Fatal("error while writing archive")
+		}
+
+		if err := atomicfile.Write(*backupOut, buf.Bytes(), 0600); err != nil {
+			logrus.WithError(err).Fatal("error while writing archive")
+		}
+
+		fmt.Fprintf(writer, "\nBackup has successfully finished, %d parameters have been saved to %s. \n", len(params), *backupOut)
+
+	case "restore":
+		r, err := os.Open(*restoreIn)
+		if err != nil {
+			logrus.WithError(err).Fatal("error while opening archive")
+		}
+		defer r.Close()
+
+		m, params, err := backup.Read(r, *restorePass)
+		if err != nil {
+			logrus.WithError(err).Fatal("error while reading archive")
+		}
+
+		total, err := strg.Restore(params, m.Path, *restoreTo)
+		if err != nil {
+			logrus.WithError(err).Fatal("error while restoring")
+		}
+
+		fmt.Fprintf(writer, "\nRestore has successfully finished, %d parameters have been (over)written to SSM parameter store. \n", total)
+
+	case "exec":
+		values, _, err := strg.Flatten(*execPath, true)
+		if err != nil {
+			logrus.WithError(err).Fatal("error while exporting")
+		}
+
+		environ, err := env.Merge(os.Environ(), values, *execPrefix, *execCase, *execKeep)
+		if err != nil {
+			logrus.WithError(err).Fatal("error while building environment")
+		}
+
+		if err := execCommand(*execArgs, environ); err != nil {
+			logrus.WithError(err).Fatal("error while executing command")
+		}
+
+	case "render":
+		mode, err := strconv.ParseUint(*renderMode, 8, 32)
+		if err != nil {
+			logrus.WithError(err).Fatal("error while parsing file mode")
+		}
+
+		text, err := ioutil.ReadFile(*renderTmpl)
+		if err != nil {
+			logrus.WithError(err).Fatal("error while reading template")
+		}
+
+		values, err := strg.Export(*renderPath, *renderDecr)
+		if err != nil {
+			logrus.WithError(err).Fatal("error while exporting")
+		}
+
+		raw, err := render.Render(*renderTmpl, string(text), values)
+		if err != nil {
+			logrus.WithError(err).Fatal("error while rendering")
+		}
+
+		if err = atomicfile.Write(*renderOut, raw, os.FileMode(mode)); err != nil {
+			logrus.WithError(err).Fatal("error while writing rendered file")
+		}
+
+		fmt.Fprintf(writer, "\nRender has successfully finished, %s has been written. \n", *renderOut)
+
+	case "diff":
+		left, leftSecure, err := loadSource(*diffLeft, diffLAWS.or(awsGlobal))
+		if err != nil {
+			logrus.WithError(err).Fatal("error while loading left source")
+		}
+
+		right, rightSecure, err := loadSource(*diffRight, diffRAWS.or(awsGlobal))
+		if err != nil {
+			logrus.WithError(err).Fatal("error while loading right source")
+		}
+
+		masked := map[string]bool{}
+		if !*diffSecrets {
+			for k := range leftSecure {
+				masked[k] = true
+			}
+			for k := range rightSecure {
+				masked[k] = true
+			}
+		}
+
+		changes := diff.Compare(left, right)
+		diff.Print(writer, changes, masked)
+
+		if *diffExit && len(changes) > 0 {
+			os.Exit(1)
+		}
+
+	case "lint":
+		r, err := source.Open(*lintJSON, int64(*maxSize))
 		if err != nil {
 			logrus.WithError(err).Fatal("error while opening file")
 		}
 		defer r.Close()
 
-		body, err := j.Flatten(r)
+		body, err := lintSource.flattener().Flatten(r)
 		if err != nil {
 			logrus.WithError(err).Fatal("error while flattering")
 		}
 
-		total, err := strg.Import(body, *putJSONMsg, *putEncrypt)
+		problems := storage.Lint(body)
+		if *backend == backendSSM {
+			quota, err := strg.Quota(context.Background(), body)
+			if err != nil {
+				logger.WithError(err).Warn("can't count stored parameters, skipping the quota check")
+			}
+			problems = append(problems, quota...)
+		}
+
+		for _, p := range problems {
+			fmt.Fprintln(writer, p)
+		}
+
+		if len(problems) > 0 {
+			fmt.Fprintf(writer, "\nLint has failed, %d problems have been found. \n", len(problems))
+			os.Exit(1)
+		}
+
+		fmt.Fprintf(writer, "\nLint has successfully finished, %d parameters are within SSM limits. \n", len(body))
+
+	case "validate":
+		tree, path, err := loadTree(*validateSrc, awsGlobal)
 		if err != nil {
-			logrus.WithError(err).Fatal("error while importing")
+			logrus.WithError(err).Fatal("error while loading source")
 		}
 
-		fmt.Fprintf(writer, "\nImport has successfully finished, %d parameters have been (over)written to SSM parameter store. \n", total)
+		total, err := validateTree(writer, *validateSch, path, tree)
+		if err != nil {
+			logrus.WithError(err).Fatal("error while validating")
+		}
+
+		if total > 0 {
+			fmt.Fprintf(writer, "\nValidation has failed, %d violations have been found. \n", total)
+			os.Exit(1)
+		}
+
+		fmt.Fprintf(writer, "\nValidation has successfully finished, %s matches the schema. \n", *validateSrc)
+
+	case "serve":
+		srv := server.New(store, logger, server.Options{
+			CacheTTL: *serveTTL,
+			Token:    *serveToken,
+			ReadOnly: *serveRO,
+			Insecure: *serveUnsafe,
+			MaxSize:  int64(*maxSize),
+		})
+
+		logger.WithField("listen", *serveListen).Info("serving parameters")
+
+		hs := &http.Server{
+			Addr:              *serveListen,
+			Handler:           srv,
+			ReadHeaderTimeout: 10 * time.Second,
+			ReadTimeout:       time.Minute,
+			WriteTimeout:      *serveWrite,
+		}
+
+		if err := hs.ListenAndServe(); err != nil {
+			logrus.WithError(err).Fatal("error while serving")
+		}
 	}
 }
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/b-b3rn4rd/json2ssm/pkg/source"
+)
+
+// mergeFiles reads JSON documents and merges them in order, see source.Merge.
+func mergeFiles(paths []string) (interface{}, error) {
+	docs := make([]interface{}, 0, len(paths))
+
+	for _, path := range paths {
+		r, err := source.Open(path, int64(*maxSize))
+		if err != nil {
+			return nil, err
+		}
+
+		doc, err := source.Decode(r)
+		r.Close()
+		if err != nil {
+			return nil, fmt.Errorf("can't parse %s: %s", path, err)
+		}
+
+		docs = append(docs, doc)
+	}
+
+	return source.Merge(docs...), nil
+}
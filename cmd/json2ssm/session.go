@@ -0,0 +1,122 @@
+package main
+
+import (
+	"github.com/alecthomas/kingpin"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/sts"
+	"github.com/sirupsen/logrus"
+)
+
+type awsOptions struct {
+	region     *string
+	profile    *string
+	endpoint   *string
+	roleARN    *string
+	externalID *string
+	mfaSerial  *string
+}
+
+func awsFlags(flag func(string, string) *kingpin.FlagClause, prefix, suffix string) *awsOptions {
+	if suffix != "" {
+		suffix = " (" + suffix + ")"
+	}
+
+	return &awsOptions{
+		region:     flag(prefix+"region", "AWS region"+suffix).String(),
+		profile:    flag(prefix+"profile", "AWS shared config profile"+suffix).String(),
+		endpoint:   flag(prefix+"endpoint-url", "Custom service endpoint URL"+suffix).String(),
+		roleARN:    flag(prefix+"role-arn", "The ARN of the IAM role to assume"+suffix).String(),
+		externalID: flag(prefix+"external-id", "The external ID passed when assuming the role"+suffix).String(),
+		mfaSerial:  flag(prefix+"mfa-serial", "The MFA device serial used when assuming the role"+suffix).String(),
+	}
+}
+
+func (o *awsOptions) empty() bool {
+	return *o.region == "" && *o.profile == "" && *o.endpoint == "" && *o.roleARN == "" && *o.externalID == "" && *o.mfaSerial == ""
+}
+
+// or fills options which were not specified with the given defaults.
+func (o *awsOptions) or(defaults *awsOptions) *awsOptions {
+	pick := func(v, d *string) *string {
+		if *v == "" {
+			return d
+		}
+
+		return v
+	}
+
+	return &awsOptions{
+		region:     pick(o.region, defaults.region),
+		profile:    pick(o.profile, defaults.profile),
+		endpoint:   pick(o.endpoint, defaults.endpoint),
+		roleARN:    pick(o.roleARN, defaults.roleARN),
+		externalID: pick(o.externalID, defaults.externalID),
+		mfaSerial:  pick(o.mfaSerial, defaults.mfaSerial),
+	}
+}
+
+// newSession returns a session along with the client configuration to use with
+// service clients, the configuration holds the endpoint and assumed role.
+func newSession(o *awsOptions) (*session.Session, *aws.Config) {
+	cfg := aws.Config{}
+	if *o.region != "" {
+		cfg.Region = o.region
+	}
+
+	sess := session.Must(session.NewSessionWithOptions(session.Options{
+		Config:            cfg,
+		Profile:           *o.profile,
+		SharedConfigState: session.SharedConfigEnable,
+	}))
+
+	client := &aws.Config{}
+	if *o.endpoint != "" {
+		client.Endpoint = o.endpoint
+	}
+
+	if *o.roleARN != "" {
+		client.Credentials = stscreds.NewCredentials(sess, *o.roleARN, func(p *stscreds.AssumeRoleProvider) {
+			if *o.externalID != "" {
+				p.ExternalID = o.externalID
+			}
+
+			if *o.mfaSerial != "" {
+				p.SerialNumber = o.mfaSerial
+				p.TokenProvider = stscreds.StdinTokenProvider
+			}
+		})
+	}
+
+	return sess, client
+}
+
+// location returns the account and region of a session, the client
+// configuration only contributes its credentials since its endpoint is SSM's.
+func location(sess *session.Session, client *aws.Config) (string, string, error) {
+	out, err := sts.New(sess, &aws.Config{Credentials: client.Credentials}).GetCallerIdentity(&sts.GetCallerIdentityInput{})
+	if err != nil {
+		return "", "", err
+	}
+
+	return aws.StringValue(out.Account), aws.StringValue(sess.Config.Region), nil
+}
+
+// distinctLocations reports whether two sessions are confirmed to use another
+// account or region, errors are logged and treated as the same location.
+func distinctLocations(sess *session.Session, client *aws.Config, destSess *session.Session, destClient *aws.Config) bool {
+	account, region, err := location(sess, client)
+	if err != nil {
+		logrus.WithError(err).Warn("can't identify the source account")
+		return false
+	}
+
+	destAccount, destRegion, err := location(destSess, destClient)
+	if err != nil {
+		logrus.WithError(err).Warn("can't identify the destination account")
+		return false
+	}
+
+	return account != destAccount || region != destRegion
+}
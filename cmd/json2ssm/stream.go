@@ -0,0 +1,35 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/b-b3rn4rd/json2ssm/pkg/source"
+	"github.com/b-b3rn4rd/json2ssm/pkg/storage"
+)
+
+// streamJSON imports a single JSON document, parameters are written while the
+// document is flattened.
+func streamJSON(strg *storage.SSMStorage) (int, error) {
+	if *backend != backendSSM {
+		return 0, fmt.Errorf("streaming is only supported by the ssm backend")
+	}
+
+	if len(*putJSONFile) != 1 || *putSource.format != formatJSON {
+		return 0, fmt.Errorf("streaming reads a single json file")
+	}
+
+	if *putInterp || *putMerged || *putSchema != "" || !putKeys.isDefault() || !putFilter.isDefault() {
+		return 0, fmt.Errorf("streaming can't be combined with --interpolate, --print-merged, --schema, key format or filter flags")
+	}
+
+	r, err := source.Open((*putJSONFile)[0], int64(*maxSize))
+	if err != nil {
+		return 0, err
+	}
+	defer r.Close()
+
+	return strg.ImportStream(context.Background(), func(ctx context.Context, records chan<- source.Record) error {
+		return source.Stream(ctx, r, records)
+	}, storage.ImportOptions{Message: *putJSONMsg, Encrypt: *putEncrypt})
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/b-b3rn4rd/json2ssm/pkg/schema"
+	"github.com/b-b3rn4rd/json2ssm/pkg/source"
+	"github.com/b-b3rn4rd/json2ssm/pkg/storage"
+)
+
+// loadTree returns the document of a source, an SSM parameter store path
+// prefixed with ssm: or a JSON document, along with the path keys are relative to.
+func loadTree(spec string, o *awsOptions) (interface{}, string, error) {
+	if strings.HasPrefix(spec, ssmScheme) {
+		path := strings.TrimPrefix(spec, ssmScheme)
+		sess, cfg := newSession(o)
+		strg := storage.New(ssm.New(sess, cfg), logger)
+		tree, err := strg.Export(path, true)
+		return tree, path, err
+	}
+
+	r, err := source.Open(spec, int64(*maxSize))
+	if err != nil {
+		return nil, "", err
+	}
+	defer r.Close()
+
+	tree, err := source.Decode(r)
+	if err != nil {
+		return nil, "", fmt.Errorf("can't parse %s: %s", spec, err)
+	}
+
+	return tree, "/", nil
+}
+
+// validateTree writes every violation of the schema to w with keys prefixed
+// by path, so that they match SSM parameter names, and returns their number.
+func validateTree(w io.Writer, schemaPath, path string, tree interface{}) (int, error) {
+	s, err := schema.Load(schemaPath)
+	if err != nil {
+		return 0, err
+	}
+
+	violations, err := s.Validate(tree)
+	if err != nil {
+		return 0, err
+	}
+
+	prefix := strings.TrimSuffix(path, "/") + "/"
+	for _, v := range violations {
+		v.Key = prefix + v.Key
+		fmt.Fprintln(w, v)
+	}
+
+	return len(violations), nil
+}
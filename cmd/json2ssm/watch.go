@@ -0,0 +1,95 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/b-b3rn4rd/json2ssm/pkg/atomicfile"
+	"github.com/b-b3rn4rd/json2ssm/pkg/storage"
+)
+
+func watchJSON(strg *storage.SSMStorage) error {
+	var last []byte
+	if *getOut != "" {
+		last, _ = ioutil.ReadFile(*getOut)
+	}
+
+	keyed, _ := getKeys.storage(strg, "").(*storage.KeyedStorage)
+
+	stop := make(chan struct{})
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-signals
+		close(stop)
+	}()
+
+	return strg.Watch(*getPath, *getDecrypt, *getInterval, stop, func(tree interface{}) error {
+		if keyed != nil {
+			var err error
+			tree, err = keyed.Unformat(tree)
+			if err != nil {
+				logger.WithError(err).Warn("can't parse parameter names, keeping the previous document")
+				return nil
+			}
+		}
+
+		if *getResolve {
+			var err error
+			tree, err = strg.ResolveRefs(context.Background(), tree, storage.ResolveOptions{Decrypt: *getDecrypt, MaxDepth: *getRefDepth})
+			if err != nil {
+				logger.WithError(err).Warn("can't resolve references, keeping the previous document")
+				return nil
+			}
+		}
+
+		if *getSchema != "" {
+			total, err := validateTree(os.Stderr, *getSchema, *getPath, tree)
+			if err != nil {
+				return err
+			}
+			if total > 0 {
+				logger.WithField("violations", total).Warn("document doesn't match the schema, keeping the previous one")
+				return nil
+			}
+		}
+
+		raw, err := json.MarshalIndent(tree, "", " ")
+		if err != nil {
+			return err
+		}
+
+		if bytes.Equal(raw, last) {
+			logger.Debug("document has not changed")
+			return nil
+		}
+
+		if *getOut == "" {
+			writer.Write(append(raw, '\n'))
+		} else if err = atomicfile.Write(*getOut, raw, 0644); err != nil {
+			return err
+		}
+
+		last = raw
+
+		if *getOnChange == "" {
+			return nil
+		}
+
+		logger.WithField("command", *getOnChange).Info("document has changed, running hook")
+
+		cmd := shellCommand(*getOnChange)
+		cmd.Stdout = os.Stderr
+		cmd.Stderr = os.Stderr
+		if err = cmd.Run(); err != nil {
+			logger.WithError(err).Warn("hook has failed")
+		}
+
+		return nil
+	})
+}
@@ -167,6 +167,84 @@ func (_m *SSMAPI) CancelCommandWithContext(_a0 aws.Context, _a1 *ssm.CancelComma
 	return r0, r1
 }
 
+// CancelMaintenanceWindowExecution provides a mock function with given fields: _a0
+func (_m *SSMAPI) CancelMaintenanceWindowExecution(_a0 *ssm.CancelMaintenanceWindowExecutionInput) (*ssm.CancelMaintenanceWindowExecutionOutput, error) {
+	ret := _m.Called(_a0)
+
+	var r0 *ssm.CancelMaintenanceWindowExecutionOutput
+	if rf, ok := ret.Get(0).(func(*ssm.CancelMaintenanceWindowExecutionInput) *ssm.CancelMaintenanceWindowExecutionOutput); ok {
+		r0 = rf(_a0)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*ssm.CancelMaintenanceWindowExecutionOutput)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(*ssm.CancelMaintenanceWindowExecutionInput) error); ok {
+		r1 = rf(_a0)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CancelMaintenanceWindowExecutionRequest provides a mock function with given fields: _a0
+func (_m *SSMAPI) CancelMaintenanceWindowExecutionRequest(_a0 *ssm.CancelMaintenanceWindowExecutionInput) (*request.Request, *ssm.CancelMaintenanceWindowExecutionOutput) {
+	ret := _m.Called(_a0)
+
+	var r0 *request.Request
+	if rf, ok := ret.Get(0).(func(*ssm.CancelMaintenanceWindowExecutionInput) *request.Request); ok {
+		r0 = rf(_a0)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*request.Request)
+		}
+	}
+
+	var r1 *ssm.CancelMaintenanceWindowExecutionOutput
+	if rf, ok := ret.Get(1).(func(*ssm.CancelMaintenanceWindowExecutionInput) *ssm.CancelMaintenanceWindowExecutionOutput); ok {
+		r1 = rf(_a0)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*ssm.CancelMaintenanceWindowExecutionOutput)
+		}
+	}
+
+	return r0, r1
+}
+
+// CancelMaintenanceWindowExecutionWithContext provides a mock function with given fields: _a0, _a1, _a2
+func (_m *SSMAPI) CancelMaintenanceWindowExecutionWithContext(_a0 aws.Context, _a1 *ssm.CancelMaintenanceWindowExecutionInput, _a2 ...request.Option) (*ssm.CancelMaintenanceWindowExecutionOutput, error) {
+	_va := make([]interface{}, len(_a2))
+	for _i := range _a2 {
+		_va[_i] = _a2[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, _a0, _a1)
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	var r0 *ssm.CancelMaintenanceWindowExecutionOutput
+	if rf, ok := ret.Get(0).(func(aws.Context, *ssm.CancelMaintenanceWindowExecutionInput, ...request.Option) *ssm.CancelMaintenanceWindowExecutionOutput); ok {
+		r0 = rf(_a0, _a1, _a2...)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*ssm.CancelMaintenanceWindowExecutionOutput)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(aws.Context, *ssm.CancelMaintenanceWindowExecutionInput, ...request.Option) error); ok {
+		r1 = rf(_a0, _a1, _a2...)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // CreateActivation provides a mock function with given fields: _a0
 func (_m *SSMAPI) CreateActivation(_a0 *ssm.CreateActivationInput) (*ssm.CreateActivationOutput, error) {
 	ret := _m.Called(_a0)
@@ -557,6 +635,84 @@ func (_m *SSMAPI) CreateMaintenanceWindowWithContext(_a0 aws.Context, _a1 *ssm.C
 	return r0, r1
 }
 
+// CreateOpsItem provides a mock function with given fields: _a0
+func (_m *SSMAPI) CreateOpsItem(_a0 *ssm.CreateOpsItemInput) (*ssm.CreateOpsItemOutput, error) {
+	ret := _m.Called(_a0)
+
+	var r0 *ssm.CreateOpsItemOutput
+	if rf, ok := ret.Get(0).(func(*ssm.CreateOpsItemInput) *ssm.CreateOpsItemOutput); ok {
+		r0 = rf(_a0)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*ssm.CreateOpsItemOutput)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(*ssm.CreateOpsItemInput) error); ok {
+		r1 = rf(_a0)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateOpsItemRequest provides a mock function with given fields: _a0
+func (_m *SSMAPI) CreateOpsItemRequest(_a0 *ssm.CreateOpsItemInput) (*request.Request, *ssm.CreateOpsItemOutput) {
+	ret := _m.Called(_a0)
+
+	var r0 *request.Request
+	if rf, ok := ret.Get(0).(func(*ssm.CreateOpsItemInput) *request.Request); ok {
+		r0 = rf(_a0)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*request.Request)
+		}
+	}
+
+	var r1 *ssm.CreateOpsItemOutput
+	if rf, ok := ret.Get(1).(func(*ssm.CreateOpsItemInput) *ssm.CreateOpsItemOutput); ok {
+		r1 = rf(_a0)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*ssm.CreateOpsItemOutput)
+		}
+	}
+
+	return r0, r1
+}
+
+// CreateOpsItemWithContext provides a mock function with given fields: _a0, _a1, _a2
+func (_m *SSMAPI) CreateOpsItemWithContext(_a0 aws.Context, _a1 *ssm.CreateOpsItemInput, _a2 ...request.Option) (*ssm.CreateOpsItemOutput, error) {
+	_va := make([]interface{}, len(_a2))
+	for _i := range _a2 {
+		_va[_i] = _a2[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, _a0, _a1)
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	var r0 *ssm.CreateOpsItemOutput
+	if rf, ok := ret.Get(0).(func(aws.Context, *ssm.CreateOpsItemInput, ...request.Option) *ssm.CreateOpsItemOutput); ok {
+		r0 = rf(_a0, _a1, _a2...)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*ssm.CreateOpsItemOutput)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(aws.Context, *ssm.CreateOpsItemInput, ...request.Option) error); ok {
+		r1 = rf(_a0, _a1, _a2...)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // CreatePatchBaseline provides a mock function with given fields: _a0
 func (_m *SSMAPI) CreatePatchBaseline(_a0 *ssm.CreatePatchBaselineInput) (*ssm.CreatePatchBaselineOutput, error) {
 	ret := _m.Called(_a0)
@@ -1863,6 +2019,162 @@ func (_m *SSMAPI) DescribeAssociation(_a0 *ssm.DescribeAssociationInput) (*ssm.D
 	return r0, r1
 }
 
+// DescribeAssociationExecutionTargets provides a mock function with given fields: _a0
+func (_m *SSMAPI) DescribeAssociationExecutionTargets(_a0 *ssm.DescribeAssociationExecutionTargetsInput) (*ssm.DescribeAssociationExecutionTargetsOutput, error) {
+	ret := _m.Called(_a0)
+
+	var r0 *ssm.DescribeAssociationExecutionTargetsOutput
+	if rf, ok := ret.Get(0).(func(*ssm.DescribeAssociationExecutionTargetsInput) *ssm.DescribeAssociationExecutionTargetsOutput); ok {
+		r0 = rf(_a0)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*ssm.DescribeAssociationExecutionTargetsOutput)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(*ssm.DescribeAssociationExecutionTargetsInput) error); ok {
+		r1 = rf(_a0)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DescribeAssociationExecutionTargetsRequest provides a mock function with given fields: _a0
+func (_m *SSMAPI) DescribeAssociationExecutionTargetsRequest(_a0 *ssm.DescribeAssociationExecutionTargetsInput) (*request.Request, *ssm.DescribeAssociationExecutionTargetsOutput) {
+	ret := _m.Called(_a0)
+
+	var r0 *request.Request
+	if rf, ok := ret.Get(0).(func(*ssm.DescribeAssociationExecutionTargetsInput) *request.Request); ok {
+		r0 = rf(_a0)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*request.Request)
+		}
+	}
+
+	var r1 *ssm.DescribeAssociationExecutionTargetsOutput
+	if rf, ok := ret.Get(1).(func(*ssm.DescribeAssociationExecutionTargetsInput) *ssm.DescribeAssociationExecutionTargetsOutput); ok {
+		r1 = rf(_a0)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*ssm.DescribeAssociationExecutionTargetsOutput)
+		}
+	}
+
+	return r0, r1
+}
+
+// DescribeAssociationExecutionTargetsWithContext provides a mock function with given fields: _a0, _a1, _a2
+func (_m *SSMAPI) DescribeAssociationExecutionTargetsWithContext(_a0 aws.Context, _a1 *ssm.DescribeAssociationExecutionTargetsInput, _a2 ...request.Option) (*ssm.DescribeAssociationExecutionTargetsOutput, error) {
+	_va := make([]interface{}, len(_a2))
+	for _i := range _a2 {
+		_va[_i] = _a2[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, _a0, _a1)
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	var r0 *ssm.DescribeAssociationExecutionTargetsOutput
+	if rf, ok := ret.Get(0).(func(aws.Context, *ssm.DescribeAssociationExecutionTargetsInput, ...request.Option) *ssm.DescribeAssociationExecutionTargetsOutput); ok {
+		r0 = rf(_a0, _a1, _a2...)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*ssm.DescribeAssociationExecutionTargetsOutput)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(aws.Context, *ssm.DescribeAssociationExecutionTargetsInput, ...request.Option) error); ok {
+		r1 = rf(_a0, _a1, _a2...)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DescribeAssociationExecutions provides a mock function with given fields: _a0
+func (_m *SSMAPI) DescribeAssociationExecutions(_a0 *ssm.DescribeAssociationExecutionsInput) (*ssm.DescribeAssociationExecutionsOutput, error) {
+	ret := _m.Called(_a0)
+
+	var r0 *ssm.DescribeAssociationExecutionsOutput
+	if rf, ok := ret.Get(0).(func(*ssm.DescribeAssociationExecutionsInput) *ssm.DescribeAssociationExecutionsOutput); ok {
+		r0 = rf(_a0)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*ssm.DescribeAssociationExecutionsOutput)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(*ssm.DescribeAssociationExecutionsInput) error); ok {
+		r1 = rf(_a0)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DescribeAssociationExecutionsRequest provides a mock function with given fields: _a0
+func (_m *SSMAPI) DescribeAssociationExecutionsRequest(_a0 *ssm.DescribeAssociationExecutionsInput) (*request.Request, *ssm.DescribeAssociationExecutionsOutput) {
+	ret := _m.Called(_a0)
+
+	var r0 *request.Request
+	if rf, ok := ret.Get(0).(func(*ssm.DescribeAssociationExecutionsInput) *request.Request); ok {
+		r0 = rf(_a0)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*request.Request)
+		}
+	}
+
+	var r1 *ssm.DescribeAssociationExecutionsOutput
+	if rf, ok := ret.Get(1).(func(*ssm.DescribeAssociationExecutionsInput) *ssm.DescribeAssociationExecutionsOutput); ok {
+		r1 = rf(_a0)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*ssm.DescribeAssociationExecutionsOutput)
+		}
+	}
+
+	return r0, r1
+}
+
+// DescribeAssociationExecutionsWithContext provides a mock function with given fields: _a0, _a1, _a2
+func (_m *SSMAPI) DescribeAssociationExecutionsWithContext(_a0 aws.Context, _a1 *ssm.DescribeAssociationExecutionsInput, _a2 ...request.Option) (*ssm.DescribeAssociationExecutionsOutput, error) {
+	_va := make([]interface{}, len(_a2))
+	for _i := range _a2 {
+		_va[_i] = _a2[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, _a0, _a1)
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	var r0 *ssm.DescribeAssociationExecutionsOutput
+	if rf, ok := ret.Get(0).(func(aws.Context, *ssm.DescribeAssociationExecutionsInput, ...request.Option) *ssm.DescribeAssociationExecutionsOutput); ok {
+		r0 = rf(_a0, _a1, _a2...)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*ssm.DescribeAssociationExecutionsOutput)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(aws.Context, *ssm.DescribeAssociationExecutionsInput, ...request.Option) error); ok {
+		r1 = rf(_a0, _a1, _a2...)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // DescribeAssociationRequest provides a mock function with given fields: _a0
 func (_m *SSMAPI) DescribeAssociationRequest(_a0 *ssm.DescribeAssociationInput) (*request.Request, *ssm.DescribeAssociationOutput) {
 	ret := _m.Called(_a0)
@@ -3201,21 +3513,21 @@ func (_m *SSMAPI) DescribeMaintenanceWindowExecutionsWithContext(_a0 aws.Context
 	return r0, r1
 }
 
-// DescribeMaintenanceWindowTargets provides a mock function with given fields: _a0
-func (_m *SSMAPI) DescribeMaintenanceWindowTargets(_a0 *ssm.DescribeMaintenanceWindowTargetsInput) (*ssm.DescribeMaintenanceWindowTargetsOutput, error) {
+// DescribeMaintenanceWindowSchedule provides a mock function with given fields: _a0
+func (_m *SSMAPI) DescribeMaintenanceWindowSchedule(_a0 *ssm.DescribeMaintenanceWindowScheduleInput) (*ssm.DescribeMaintenanceWindowScheduleOutput, error) {
 	ret := _m.Called(_a0)
 
-	var r0 *ssm.DescribeMaintenanceWindowTargetsOutput
-	if rf, ok := ret.Get(0).(func(*ssm.DescribeMaintenanceWindowTargetsInput) *ssm.DescribeMaintenanceWindowTargetsOutput); ok {
+	var r0 *ssm.DescribeMaintenanceWindowScheduleOutput
+	if rf, ok := ret.Get(0).(func(*ssm.DescribeMaintenanceWindowScheduleInput) *ssm.DescribeMaintenanceWindowScheduleOutput); ok {
 		r0 = rf(_a0)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*ssm.DescribeMaintenanceWindowTargetsOutput)
+			r0 = ret.Get(0).(*ssm.DescribeMaintenanceWindowScheduleOutput)
 		}
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(*ssm.DescribeMaintenanceWindowTargetsInput) error); ok {
+	if rf, ok := ret.Get(1).(func(*ssm.DescribeMaintenanceWindowScheduleInput) error); ok {
 		r1 = rf(_a0)
 	} else {
 		r1 = ret.Error(1)
@@ -3224,12 +3536,12 @@ func (_m *SSMAPI) DescribeMaintenanceWindowTargets(_a0 *ssm.DescribeMaintenanceW
 	return r0, r1
 }
 
-// DescribeMaintenanceWindowTargetsRequest provides a mock function with given fields: _a0
-func (_m *SSMAPI) DescribeMaintenanceWindowTargetsRequest(_a0 *ssm.DescribeMaintenanceWindowTargetsInput) (*request.Request, *ssm.DescribeMaintenanceWindowTargetsOutput) {
+// DescribeMaintenanceWindowScheduleRequest provides a mock function with given fields: _a0
+func (_m *SSMAPI) DescribeMaintenanceWindowScheduleRequest(_a0 *ssm.DescribeMaintenanceWindowScheduleInput) (*request.Request, *ssm.DescribeMaintenanceWindowScheduleOutput) {
 	ret := _m.Called(_a0)
 
 	var r0 *request.Request
-	if rf, ok := ret.Get(0).(func(*ssm.DescribeMaintenanceWindowTargetsInput) *request.Request); ok {
+	if rf, ok := ret.Get(0).(func(*ssm.DescribeMaintenanceWindowScheduleInput) *request.Request); ok {
 		r0 = rf(_a0)
 	} else {
 		if ret.Get(0) != nil {
@@ -3237,20 +3549,20 @@ func (_m *SSMAPI) DescribeMaintenanceWindowTargetsRequest(_a0 *ssm.DescribeMaint
 		}
 	}
 
-	var r1 *ssm.DescribeMaintenanceWindowTargetsOutput
-	if rf, ok := ret.Get(1).(func(*ssm.DescribeMaintenanceWindowTargetsInput) *ssm.DescribeMaintenanceWindowTargetsOutput); ok {
+	var r1 *ssm.DescribeMaintenanceWindowScheduleOutput
+	if rf, ok := ret.Get(1).(func(*ssm.DescribeMaintenanceWindowScheduleInput) *ssm.DescribeMaintenanceWindowScheduleOutput); ok {
 		r1 = rf(_a0)
 	} else {
 		if ret.Get(1) != nil {
-			r1 = ret.Get(1).(*ssm.DescribeMaintenanceWindowTargetsOutput)
+			r1 = ret.Get(1).(*ssm.DescribeMaintenanceWindowScheduleOutput)
 		}
 	}
 
 	return r0, r1
 }
 
-// DescribeMaintenanceWindowTargetsWithContext provides a mock function with given fields: _a0, _a1, _a2
-func (_m *SSMAPI) DescribeMaintenanceWindowTargetsWithContext(_a0 aws.Context, _a1 *ssm.DescribeMaintenanceWindowTargetsInput, _a2 ...request.Option) (*ssm.DescribeMaintenanceWindowTargetsOutput, error) {
+// DescribeMaintenanceWindowScheduleWithContext provides a mock function with given fields: _a0, _a1, _a2
+func (_m *SSMAPI) DescribeMaintenanceWindowScheduleWithContext(_a0 aws.Context, _a1 *ssm.DescribeMaintenanceWindowScheduleInput, _a2 ...request.Option) (*ssm.DescribeMaintenanceWindowScheduleOutput, error) {
 	_va := make([]interface{}, len(_a2))
 	for _i := range _a2 {
 		_va[_i] = _a2[_i]
@@ -3260,8 +3572,86 @@ func (_m *SSMAPI) DescribeMaintenanceWindowTargetsWithContext(_a0 aws.Context, _
 	_ca = append(_ca, _va...)
 	ret := _m.Called(_ca...)
 
-	var r0 *ssm.DescribeMaintenanceWindowTargetsOutput
-	if rf, ok := ret.Get(0).(func(aws.Context, *ssm.DescribeMaintenanceWindowTargetsInput, ...request.Option) *ssm.DescribeMaintenanceWindowTargetsOutput); ok {
+	var r0 *ssm.DescribeMaintenanceWindowScheduleOutput
+	if rf, ok := ret.Get(0).(func(aws.Context, *ssm.DescribeMaintenanceWindowScheduleInput, ...request.Option) *ssm.DescribeMaintenanceWindowScheduleOutput); ok {
+		r0 = rf(_a0, _a1, _a2...)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*ssm.DescribeMaintenanceWindowScheduleOutput)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(aws.Context, *ssm.DescribeMaintenanceWindowScheduleInput, ...request.Option) error); ok {
+		r1 = rf(_a0, _a1, _a2...)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DescribeMaintenanceWindowTargets provides a mock function with given fields: _a0
+func (_m *SSMAPI) DescribeMaintenanceWindowTargets(_a0 *ssm.DescribeMaintenanceWindowTargetsInput) (*ssm.DescribeMaintenanceWindowTargetsOutput, error) {
+	ret := _m.Called(_a0)
+
+	var r0 *ssm.DescribeMaintenanceWindowTargetsOutput
+	if rf, ok := ret.Get(0).(func(*ssm.DescribeMaintenanceWindowTargetsInput) *ssm.DescribeMaintenanceWindowTargetsOutput); ok {
+		r0 = rf(_a0)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*ssm.DescribeMaintenanceWindowTargetsOutput)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(*ssm.DescribeMaintenanceWindowTargetsInput) error); ok {
+		r1 = rf(_a0)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DescribeMaintenanceWindowTargetsRequest provides a mock function with given fields: _a0
+func (_m *SSMAPI) DescribeMaintenanceWindowTargetsRequest(_a0 *ssm.DescribeMaintenanceWindowTargetsInput) (*request.Request, *ssm.DescribeMaintenanceWindowTargetsOutput) {
+	ret := _m.Called(_a0)
+
+	var r0 *request.Request
+	if rf, ok := ret.Get(0).(func(*ssm.DescribeMaintenanceWindowTargetsInput) *request.Request); ok {
+		r0 = rf(_a0)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*request.Request)
+		}
+	}
+
+	var r1 *ssm.DescribeMaintenanceWindowTargetsOutput
+	if rf, ok := ret.Get(1).(func(*ssm.DescribeMaintenanceWindowTargetsInput) *ssm.DescribeMaintenanceWindowTargetsOutput); ok {
+		r1 = rf(_a0)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*ssm.DescribeMaintenanceWindowTargetsOutput)
+		}
+	}
+
+	return r0, r1
+}
+
+// DescribeMaintenanceWindowTargetsWithContext provides a mock function with given fields: _a0, _a1, _a2
+func (_m *SSMAPI) DescribeMaintenanceWindowTargetsWithContext(_a0 aws.Context, _a1 *ssm.DescribeMaintenanceWindowTargetsInput, _a2 ...request.Option) (*ssm.DescribeMaintenanceWindowTargetsOutput, error) {
+	_va := make([]interface{}, len(_a2))
+	for _i := range _a2 {
+		_va[_i] = _a2[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, _a0, _a1)
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	var r0 *ssm.DescribeMaintenanceWindowTargetsOutput
+	if rf, ok := ret.Get(0).(func(aws.Context, *ssm.DescribeMaintenanceWindowTargetsInput, ...request.Option) *ssm.DescribeMaintenanceWindowTargetsOutput); ok {
 		r0 = rf(_a0, _a1, _a2...)
 	} else {
 		if ret.Get(0) != nil {
@@ -3380,6 +3770,84 @@ func (_m *SSMAPI) DescribeMaintenanceWindows(_a0 *ssm.DescribeMaintenanceWindows
 	return r0, r1
 }
 
+// DescribeMaintenanceWindowsForTarget provides a mock function with given fields: _a0
+func (_m *SSMAPI) DescribeMaintenanceWindowsForTarget(_a0 *ssm.DescribeMaintenanceWindowsForTargetInput) (*ssm.DescribeMaintenanceWindowsForTargetOutput, error) {
+	ret := _m.Called(_a0)
+
+	var r0 *ssm.DescribeMaintenanceWindowsForTargetOutput
+	if rf, ok := ret.Get(0).(func(*ssm.DescribeMaintenanceWindowsForTargetInput) *ssm.DescribeMaintenanceWindowsForTargetOutput); ok {
+		r0 = rf(_a0)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*ssm.DescribeMaintenanceWindowsForTargetOutput)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(*ssm.DescribeMaintenanceWindowsForTargetInput) error); ok {
+		r1 = rf(_a0)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DescribeMaintenanceWindowsForTargetRequest provides a mock function with given fields: _a0
+func (_m *SSMAPI) DescribeMaintenanceWindowsForTargetRequest(_a0 *ssm.DescribeMaintenanceWindowsForTargetInput) (*request.Request, *ssm.DescribeMaintenanceWindowsForTargetOutput) {
+	ret := _m.Called(_a0)
+
+	var r0 *request.Request
+	if rf, ok := ret.Get(0).(func(*ssm.DescribeMaintenanceWindowsForTargetInput) *request.Request); ok {
+		r0 = rf(_a0)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*request.Request)
+		}
+	}
+
+	var r1 *ssm.DescribeMaintenanceWindowsForTargetOutput
+	if rf, ok := ret.Get(1).(func(*ssm.DescribeMaintenanceWindowsForTargetInput) *ssm.DescribeMaintenanceWindowsForTargetOutput); ok {
+		r1 = rf(_a0)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*ssm.DescribeMaintenanceWindowsForTargetOutput)
+		}
+	}
+
+	return r0, r1
+}
+
+// DescribeMaintenanceWindowsForTargetWithContext provides a mock function with given fields: _a0, _a1, _a2
+func (_m *SSMAPI) DescribeMaintenanceWindowsForTargetWithContext(_a0 aws.Context, _a1 *ssm.DescribeMaintenanceWindowsForTargetInput, _a2 ...request.Option) (*ssm.DescribeMaintenanceWindowsForTargetOutput, error) {
+	_va := make([]interface{}, len(_a2))
+	for _i := range _a2 {
+		_va[_i] = _a2[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, _a0, _a1)
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	var r0 *ssm.DescribeMaintenanceWindowsForTargetOutput
+	if rf, ok := ret.Get(0).(func(aws.Context, *ssm.DescribeMaintenanceWindowsForTargetInput, ...request.Option) *ssm.DescribeMaintenanceWindowsForTargetOutput); ok {
+		r0 = rf(_a0, _a1, _a2...)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*ssm.DescribeMaintenanceWindowsForTargetOutput)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(aws.Context, *ssm.DescribeMaintenanceWindowsForTargetInput, ...request.Option) error); ok {
+		r1 = rf(_a0, _a1, _a2...)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // DescribeMaintenanceWindowsRequest provides a mock function with given fields: _a0
 func (_m *SSMAPI) DescribeMaintenanceWindowsRequest(_a0 *ssm.DescribeMaintenanceWindowsInput) (*request.Request, *ssm.DescribeMaintenanceWindowsOutput) {
 	ret := _m.Called(_a0)
@@ -3435,6 +3903,84 @@ func (_m *SSMAPI) DescribeMaintenanceWindowsWithContext(_a0 aws.Context, _a1 *ss
 	return r0, r1
 }
 
+// DescribeOpsItems provides a mock function with given fields: _a0
+func (_m *SSMAPI) DescribeOpsItems(_a0 *ssm.DescribeOpsItemsInput) (*ssm.DescribeOpsItemsOutput, error) {
+	ret := _m.Called(_a0)
+
+	var r0 *ssm.DescribeOpsItemsOutput
+	if rf, ok := ret.Get(0).(func(*ssm.DescribeOpsItemsInput) *ssm.DescribeOpsItemsOutput); ok {
+		r0 = rf(_a0)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*ssm.DescribeOpsItemsOutput)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(*ssm.DescribeOpsItemsInput) error); ok {
+		r1 = rf(_a0)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DescribeOpsItemsRequest provides a mock function with given fields: _a0
+func (_m *SSMAPI) DescribeOpsItemsRequest(_a0 *ssm.DescribeOpsItemsInput) (*request.Request, *ssm.DescribeOpsItemsOutput) {
+	ret := _m.Called(_a0)
+
+	var r0 *request.Request
+	if rf, ok := ret.Get(0).(func(*ssm.DescribeOpsItemsInput) *request.Request); ok {
+		r0 = rf(_a0)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*request.Request)
+		}
+	}
+
+	var r1 *ssm.DescribeOpsItemsOutput
+	if rf, ok := ret.Get(1).(func(*ssm.DescribeOpsItemsInput) *ssm.DescribeOpsItemsOutput); ok {
+		r1 = rf(_a0)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*ssm.DescribeOpsItemsOutput)
+		}
+	}
+
+	return r0, r1
+}
+
+// DescribeOpsItemsWithContext provides a mock function with given fields: _a0, _a1, _a2
+func (_m *SSMAPI) DescribeOpsItemsWithContext(_a0 aws.Context, _a1 *ssm.DescribeOpsItemsInput, _a2 ...request.Option) (*ssm.DescribeOpsItemsOutput, error) {
+	_va := make([]interface{}, len(_a2))
+	for _i := range _a2 {
+		_va[_i] = _a2[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, _a0, _a1)
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	var r0 *ssm.DescribeOpsItemsOutput
+	if rf, ok := ret.Get(0).(func(aws.Context, *ssm.DescribeOpsItemsInput, ...request.Option) *ssm.DescribeOpsItemsOutput); ok {
+		r0 = rf(_a0, _a1, _a2...)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*ssm.DescribeOpsItemsOutput)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(aws.Context, *ssm.DescribeOpsItemsInput, ...request.Option) error); ok {
+		r1 = rf(_a0, _a1, _a2...)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // DescribeParameters provides a mock function with given fields: _a0
 func (_m *SSMAPI) DescribeParameters(_a0 *ssm.DescribeParametersInput) (*ssm.DescribeParametersOutput, error) {
 	ret := _m.Called(_a0)
@@ -3782,21 +4328,21 @@ func (_m *SSMAPI) DescribePatchGroupsWithContext(_a0 aws.Context, _a1 *ssm.Descr
 	return r0, r1
 }
 
-// GetAutomationExecution provides a mock function with given fields: _a0
-func (_m *SSMAPI) GetAutomationExecution(_a0 *ssm.GetAutomationExecutionInput) (*ssm.GetAutomationExecutionOutput, error) {
+// DescribePatchProperties provides a mock function with given fields: _a0
+func (_m *SSMAPI) DescribePatchProperties(_a0 *ssm.DescribePatchPropertiesInput) (*ssm.DescribePatchPropertiesOutput, error) {
 	ret := _m.Called(_a0)
 
-	var r0 *ssm.GetAutomationExecutionOutput
-	if rf, ok := ret.Get(0).(func(*ssm.GetAutomationExecutionInput) *ssm.GetAutomationExecutionOutput); ok {
+	var r0 *ssm.DescribePatchPropertiesOutput
+	if rf, ok := ret.Get(0).(func(*ssm.DescribePatchPropertiesInput) *ssm.DescribePatchPropertiesOutput); ok {
 		r0 = rf(_a0)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*ssm.GetAutomationExecutionOutput)
+			r0 = ret.Get(0).(*ssm.DescribePatchPropertiesOutput)
 		}
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(*ssm.GetAutomationExecutionInput) error); ok {
+	if rf, ok := ret.Get(1).(func(*ssm.DescribePatchPropertiesInput) error); ok {
 		r1 = rf(_a0)
 	} else {
 		r1 = ret.Error(1)
@@ -3805,12 +4351,12 @@ func (_m *SSMAPI) GetAutomationExecution(_a0 *ssm.GetAutomationExecutionInput) (
 	return r0, r1
 }
 
-// GetAutomationExecutionRequest provides a mock function with given fields: _a0
-func (_m *SSMAPI) GetAutomationExecutionRequest(_a0 *ssm.GetAutomationExecutionInput) (*request.Request, *ssm.GetAutomationExecutionOutput) {
+// DescribePatchPropertiesRequest provides a mock function with given fields: _a0
+func (_m *SSMAPI) DescribePatchPropertiesRequest(_a0 *ssm.DescribePatchPropertiesInput) (*request.Request, *ssm.DescribePatchPropertiesOutput) {
 	ret := _m.Called(_a0)
 
 	var r0 *request.Request
-	if rf, ok := ret.Get(0).(func(*ssm.GetAutomationExecutionInput) *request.Request); ok {
+	if rf, ok := ret.Get(0).(func(*ssm.DescribePatchPropertiesInput) *request.Request); ok {
 		r0 = rf(_a0)
 	} else {
 		if ret.Get(0) != nil {
@@ -3818,20 +4364,20 @@ func (_m *SSMAPI) GetAutomationExecutionRequest(_a0 *ssm.GetAutomationExecutionI
 		}
 	}
 
-	var r1 *ssm.GetAutomationExecutionOutput
-	if rf, ok := ret.Get(1).(func(*ssm.GetAutomationExecutionInput) *ssm.GetAutomationExecutionOutput); ok {
+	var r1 *ssm.DescribePatchPropertiesOutput
+	if rf, ok := ret.Get(1).(func(*ssm.DescribePatchPropertiesInput) *ssm.DescribePatchPropertiesOutput); ok {
 		r1 = rf(_a0)
 	} else {
 		if ret.Get(1) != nil {
-			r1 = ret.Get(1).(*ssm.GetAutomationExecutionOutput)
+			r1 = ret.Get(1).(*ssm.DescribePatchPropertiesOutput)
 		}
 	}
 
 	return r0, r1
 }
 
-// GetAutomationExecutionWithContext provides a mock function with given fields: _a0, _a1, _a2
-func (_m *SSMAPI) GetAutomationExecutionWithContext(_a0 aws.Context, _a1 *ssm.GetAutomationExecutionInput, _a2 ...request.Option) (*ssm.GetAutomationExecutionOutput, error) {
+// DescribePatchPropertiesWithContext provides a mock function with given fields: _a0, _a1, _a2
+func (_m *SSMAPI) DescribePatchPropertiesWithContext(_a0 aws.Context, _a1 *ssm.DescribePatchPropertiesInput, _a2 ...request.Option) (*ssm.DescribePatchPropertiesOutput, error) {
 	_va := make([]interface{}, len(_a2))
 	for _i := range _a2 {
 		_va[_i] = _a2[_i]
@@ -3841,17 +4387,17 @@ func (_m *SSMAPI) GetAutomationExecutionWithContext(_a0 aws.Context, _a1 *ssm.Ge
 	_ca = append(_ca, _va...)
 	ret := _m.Called(_ca...)
 
-	var r0 *ssm.GetAutomationExecutionOutput
-	if rf, ok := ret.Get(0).(func(aws.Context, *ssm.GetAutomationExecutionInput, ...request.Option) *ssm.GetAutomationExecutionOutput); ok {
+	var r0 *ssm.DescribePatchPropertiesOutput
+	if rf, ok := ret.Get(0).(func(aws.Context, *ssm.DescribePatchPropertiesInput, ...request.Option) *ssm.DescribePatchPropertiesOutput); ok {
 		r0 = rf(_a0, _a1, _a2...)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*ssm.GetAutomationExecutionOutput)
+			r0 = ret.Get(0).(*ssm.DescribePatchPropertiesOutput)
 		}
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(aws.Context, *ssm.GetAutomationExecutionInput, ...request.Option) error); ok {
+	if rf, ok := ret.Get(1).(func(aws.Context, *ssm.DescribePatchPropertiesInput, ...request.Option) error); ok {
 		r1 = rf(_a0, _a1, _a2...)
 	} else {
 		r1 = ret.Error(1)
@@ -3860,21 +4406,21 @@ func (_m *SSMAPI) GetAutomationExecutionWithContext(_a0 aws.Context, _a1 *ssm.Ge
 	return r0, r1
 }
 
-// GetCommandInvocation provides a mock function with given fields: _a0
-func (_m *SSMAPI) GetCommandInvocation(_a0 *ssm.GetCommandInvocationInput) (*ssm.GetCommandInvocationOutput, error) {
+// DescribeSessions provides a mock function with given fields: _a0
+func (_m *SSMAPI) DescribeSessions(_a0 *ssm.DescribeSessionsInput) (*ssm.DescribeSessionsOutput, error) {
 	ret := _m.Called(_a0)
 
-	var r0 *ssm.GetCommandInvocationOutput
-	if rf, ok := ret.Get(0).(func(*ssm.GetCommandInvocationInput) *ssm.GetCommandInvocationOutput); ok {
+	var r0 *ssm.DescribeSessionsOutput
+	if rf, ok := ret.Get(0).(func(*ssm.DescribeSessionsInput) *ssm.DescribeSessionsOutput); ok {
 		r0 = rf(_a0)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*ssm.GetCommandInvocationOutput)
+			r0 = ret.Get(0).(*ssm.DescribeSessionsOutput)
 		}
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(*ssm.GetCommandInvocationInput) error); ok {
+	if rf, ok := ret.Get(1).(func(*ssm.DescribeSessionsInput) error); ok {
 		r1 = rf(_a0)
 	} else {
 		r1 = ret.Error(1)
@@ -3883,12 +4429,12 @@ func (_m *SSMAPI) GetCommandInvocation(_a0 *ssm.GetCommandInvocationInput) (*ssm
 	return r0, r1
 }
 
-// GetCommandInvocationRequest provides a mock function with given fields: _a0
-func (_m *SSMAPI) GetCommandInvocationRequest(_a0 *ssm.GetCommandInvocationInput) (*request.Request, *ssm.GetCommandInvocationOutput) {
+// DescribeSessionsRequest provides a mock function with given fields: _a0
+func (_m *SSMAPI) DescribeSessionsRequest(_a0 *ssm.DescribeSessionsInput) (*request.Request, *ssm.DescribeSessionsOutput) {
 	ret := _m.Called(_a0)
 
 	var r0 *request.Request
-	if rf, ok := ret.Get(0).(func(*ssm.GetCommandInvocationInput) *request.Request); ok {
+	if rf, ok := ret.Get(0).(func(*ssm.DescribeSessionsInput) *request.Request); ok {
 		r0 = rf(_a0)
 	} else {
 		if ret.Get(0) != nil {
@@ -3896,20 +4442,20 @@ func (_m *SSMAPI) GetCommandInvocationRequest(_a0 *ssm.GetCommandInvocationInput
 		}
 	}
 
-	var r1 *ssm.GetCommandInvocationOutput
-	if rf, ok := ret.Get(1).(func(*ssm.GetCommandInvocationInput) *ssm.GetCommandInvocationOutput); ok {
+	var r1 *ssm.DescribeSessionsOutput
+	if rf, ok := ret.Get(1).(func(*ssm.DescribeSessionsInput) *ssm.DescribeSessionsOutput); ok {
 		r1 = rf(_a0)
 	} else {
 		if ret.Get(1) != nil {
-			r1 = ret.Get(1).(*ssm.GetCommandInvocationOutput)
+			r1 = ret.Get(1).(*ssm.DescribeSessionsOutput)
 		}
 	}
 
 	return r0, r1
 }
 
-// GetCommandInvocationWithContext provides a mock function with given fields: _a0, _a1, _a2
-func (_m *SSMAPI) GetCommandInvocationWithContext(_a0 aws.Context, _a1 *ssm.GetCommandInvocationInput, _a2 ...request.Option) (*ssm.GetCommandInvocationOutput, error) {
+// DescribeSessionsWithContext provides a mock function with given fields: _a0, _a1, _a2
+func (_m *SSMAPI) DescribeSessionsWithContext(_a0 aws.Context, _a1 *ssm.DescribeSessionsInput, _a2 ...request.Option) (*ssm.DescribeSessionsOutput, error) {
 	_va := make([]interface{}, len(_a2))
 	for _i := range _a2 {
 		_va[_i] = _a2[_i]
@@ -3919,17 +4465,17 @@ func (_m *SSMAPI) GetCommandInvocationWithContext(_a0 aws.Context, _a1 *ssm.GetC
 	_ca = append(_ca, _va...)
 	ret := _m.Called(_ca...)
 
-	var r0 *ssm.GetCommandInvocationOutput
-	if rf, ok := ret.Get(0).(func(aws.Context, *ssm.GetCommandInvocationInput, ...request.Option) *ssm.GetCommandInvocationOutput); ok {
+	var r0 *ssm.DescribeSessionsOutput
+	if rf, ok := ret.Get(0).(func(aws.Context, *ssm.DescribeSessionsInput, ...request.Option) *ssm.DescribeSessionsOutput); ok {
 		r0 = rf(_a0, _a1, _a2...)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*ssm.GetCommandInvocationOutput)
+			r0 = ret.Get(0).(*ssm.DescribeSessionsOutput)
 		}
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(aws.Context, *ssm.GetCommandInvocationInput, ...request.Option) error); ok {
+	if rf, ok := ret.Get(1).(func(aws.Context, *ssm.DescribeSessionsInput, ...request.Option) error); ok {
 		r1 = rf(_a0, _a1, _a2...)
 	} else {
 		r1 = ret.Error(1)
@@ -3938,21 +4484,21 @@ func (_m *SSMAPI) GetCommandInvocationWithContext(_a0 aws.Context, _a1 *ssm.GetC
 	return r0, r1
 }
 
-// GetDefaultPatchBaseline provides a mock function with given fields: _a0
-func (_m *SSMAPI) GetDefaultPatchBaseline(_a0 *ssm.GetDefaultPatchBaselineInput) (*ssm.GetDefaultPatchBaselineOutput, error) {
+// GetAutomationExecution provides a mock function with given fields: _a0
+func (_m *SSMAPI) GetAutomationExecution(_a0 *ssm.GetAutomationExecutionInput) (*ssm.GetAutomationExecutionOutput, error) {
 	ret := _m.Called(_a0)
 
-	var r0 *ssm.GetDefaultPatchBaselineOutput
-	if rf, ok := ret.Get(0).(func(*ssm.GetDefaultPatchBaselineInput) *ssm.GetDefaultPatchBaselineOutput); ok {
+	var r0 *ssm.GetAutomationExecutionOutput
+	if rf, ok := ret.Get(0).(func(*ssm.GetAutomationExecutionInput) *ssm.GetAutomationExecutionOutput); ok {
 		r0 = rf(_a0)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*ssm.GetDefaultPatchBaselineOutput)
+			r0 = ret.Get(0).(*ssm.GetAutomationExecutionOutput)
 		}
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(*ssm.GetDefaultPatchBaselineInput) error); ok {
+	if rf, ok := ret.Get(1).(func(*ssm.GetAutomationExecutionInput) error); ok {
 		r1 = rf(_a0)
 	} else {
 		r1 = ret.Error(1)
@@ -3961,12 +4507,12 @@ func (_m *SSMAPI) GetDefaultPatchBaseline(_a0 *ssm.GetDefaultPatchBaselineInput)
 	return r0, r1
 }
 
-// GetDefaultPatchBaselineRequest provides a mock function with given fields: _a0
-func (_m *SSMAPI) GetDefaultPatchBaselineRequest(_a0 *ssm.GetDefaultPatchBaselineInput) (*request.Request, *ssm.GetDefaultPatchBaselineOutput) {
+// GetAutomationExecutionRequest provides a mock function with given fields: _a0
+func (_m *SSMAPI) GetAutomationExecutionRequest(_a0 *ssm.GetAutomationExecutionInput) (*request.Request, *ssm.GetAutomationExecutionOutput) {
 	ret := _m.Called(_a0)
 
 	var r0 *request.Request
-	if rf, ok := ret.Get(0).(func(*ssm.GetDefaultPatchBaselineInput) *request.Request); ok {
+	if rf, ok := ret.Get(0).(func(*ssm.GetAutomationExecutionInput) *request.Request); ok {
 		r0 = rf(_a0)
 	} else {
 		if ret.Get(0) != nil {
@@ -3974,12 +4520,246 @@ func (_m *SSMAPI) GetDefaultPatchBaselineRequest(_a0 *ssm.GetDefaultPatchBaselin
 		}
 	}
 
-	var r1 *ssm.GetDefaultPatchBaselineOutput
-	if rf, ok := ret.Get(1).(func(*ssm.GetDefaultPatchBaselineInput) *ssm.GetDefaultPatchBaselineOutput); ok {
+	var r1 *ssm.GetAutomationExecutionOutput
+	if rf, ok := ret.Get(1).(func(*ssm.GetAutomationExecutionInput) *ssm.GetAutomationExecutionOutput); ok {
 		r1 = rf(_a0)
 	} else {
 		if ret.Get(1) != nil {
-			r1 = ret.Get(1).(*ssm.GetDefaultPatchBaselineOutput)
+			r1 = ret.Get(1).(*ssm.GetAutomationExecutionOutput)
+		}
+	}
+
+	return r0, r1
+}
+
+// GetAutomationExecutionWithContext provides a mock function with given fields: _a0, _a1, _a2
+func (_m *SSMAPI) GetAutomationExecutionWithContext(_a0 aws.Context, _a1 *ssm.GetAutomationExecutionInput, _a2 ...request.Option) (*ssm.GetAutomationExecutionOutput, error) {
+	_va := make([]interface{}, len(_a2))
+	for _i := range _a2 {
+		_va[_i] = _a2[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, _a0, _a1)
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	var r0 *ssm.GetAutomationExecutionOutput
+	if rf, ok := ret.Get(0).(func(aws.Context, *ssm.GetAutomationExecutionInput, ...request.Option) *ssm.GetAutomationExecutionOutput); ok {
+		r0 = rf(_a0, _a1, _a2...)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*ssm.GetAutomationExecutionOutput)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(aws.Context, *ssm.GetAutomationExecutionInput, ...request.Option) error); ok {
+		r1 = rf(_a0, _a1, _a2...)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetCommandInvocation provides a mock function with given fields: _a0
+func (_m *SSMAPI) GetCommandInvocation(_a0 *ssm.GetCommandInvocationInput) (*ssm.GetCommandInvocationOutput, error) {
+	ret := _m.Called(_a0)
+
+	var r0 *ssm.GetCommandInvocationOutput
+	if rf, ok := ret.Get(0).(func(*ssm.GetCommandInvocationInput) *ssm.GetCommandInvocationOutput); ok {
+		r0 = rf(_a0)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*ssm.GetCommandInvocationOutput)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(*ssm.GetCommandInvocationInput) error); ok {
+		r1 = rf(_a0)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetCommandInvocationRequest provides a mock function with given fields: _a0
+func (_m *SSMAPI) GetCommandInvocationRequest(_a0 *ssm.GetCommandInvocationInput) (*request.Request, *ssm.GetCommandInvocationOutput) {
+	ret := _m.Called(_a0)
+
+	var r0 *request.Request
+	if rf, ok := ret.Get(0).(func(*ssm.GetCommandInvocationInput) *request.Request); ok {
+		r0 = rf(_a0)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*request.Request)
+		}
+	}
+
+	var r1 *ssm.GetCommandInvocationOutput
+	if rf, ok := ret.Get(1).(func(*ssm.GetCommandInvocationInput) *ssm.GetCommandInvocationOutput); ok {
+		r1 = rf(_a0)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*ssm.GetCommandInvocationOutput)
+		}
+	}
+
+	return r0, r1
+}
+
+// GetCommandInvocationWithContext provides a mock function with given fields: _a0, _a1, _a2
+func (_m *SSMAPI) GetCommandInvocationWithContext(_a0 aws.Context, _a1 *ssm.GetCommandInvocationInput, _a2 ...request.Option) (*ssm.GetCommandInvocationOutput, error) {
+	_va := make([]interface{}, len(_a2))
+	for _i := range _a2 {
+		_va[_i] = _a2[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, _a0, _a1)
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	var r0 *ssm.GetCommandInvocationOutput
+	if rf, ok := ret.Get(0).(func(aws.Context, *ssm.GetCommandInvocationInput, ...request.Option) *ssm.GetCommandInvocationOutput); ok {
+		r0 = rf(_a0, _a1, _a2...)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*ssm.GetCommandInvocationOutput)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(aws.Context, *ssm.GetCommandInvocationInput, ...request.Option) error); ok {
+		r1 = rf(_a0, _a1, _a2...)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetConnectionStatus provides a mock function with given fields: _a0
+func (_m *SSMAPI) GetConnectionStatus(_a0 *ssm.GetConnectionStatusInput) (*ssm.GetConnectionStatusOutput, error) {
+	ret := _m.Called(_a0)
+
+	var r0 *ssm.GetConnectionStatusOutput
+	if rf, ok := ret.Get(0).(func(*ssm.GetConnectionStatusInput) *ssm.GetConnectionStatusOutput); ok {
+		r0 = rf(_a0)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*ssm.GetConnectionStatusOutput)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(*ssm.GetConnectionStatusInput) error); ok {
+		r1 = rf(_a0)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetConnectionStatusRequest provides a mock function with given fields: _a0
+func (_m *SSMAPI) GetConnectionStatusRequest(_a0 *ssm.GetConnectionStatusInput) (*request.Request, *ssm.GetConnectionStatusOutput) {
+	ret := _m.Called(_a0)
+
+	var r0 *request.Request
+	if rf, ok := ret.Get(0).(func(*ssm.GetConnectionStatusInput) *request.Request); ok {
+		r0 = rf(_a0)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*request.Request)
+		}
+	}
+
+	var r1 *ssm.GetConnectionStatusOutput
+	if rf, ok := ret.Get(1).(func(*ssm.GetConnectionStatusInput) *ssm.GetConnectionStatusOutput); ok {
+		r1 = rf(_a0)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*ssm.GetConnectionStatusOutput)
+		}
+	}
+
+	return r0, r1
+}
+
+// GetConnectionStatusWithContext provides a mock function with given fields: _a0, _a1, _a2
+func (_m *SSMAPI) GetConnectionStatusWithContext(_a0 aws.Context, _a1 *ssm.GetConnectionStatusInput, _a2 ...request.Option) (*ssm.GetConnectionStatusOutput, error) {
+	_va := make([]interface{}, len(_a2))
+	for _i := range _a2 {
+		_va[_i] = _a2[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, _a0, _a1)
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	var r0 *ssm.GetConnectionStatusOutput
+	if rf, ok := ret.Get(0).(func(aws.Context, *ssm.GetConnectionStatusInput, ...request.Option) *ssm.GetConnectionStatusOutput); ok {
+		r0 = rf(_a0, _a1, _a2...)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*ssm.GetConnectionStatusOutput)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(aws.Context, *ssm.GetConnectionStatusInput, ...request.Option) error); ok {
+		r1 = rf(_a0, _a1, _a2...)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetDefaultPatchBaseline provides a mock function with given fields: _a0
+func (_m *SSMAPI) GetDefaultPatchBaseline(_a0 *ssm.GetDefaultPatchBaselineInput) (*ssm.GetDefaultPatchBaselineOutput, error) {
+	ret := _m.Called(_a0)
+
+	var r0 *ssm.GetDefaultPatchBaselineOutput
+	if rf, ok := ret.Get(0).(func(*ssm.GetDefaultPatchBaselineInput) *ssm.GetDefaultPatchBaselineOutput); ok {
+		r0 = rf(_a0)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*ssm.GetDefaultPatchBaselineOutput)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(*ssm.GetDefaultPatchBaselineInput) error); ok {
+		r1 = rf(_a0)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetDefaultPatchBaselineRequest provides a mock function with given fields: _a0
+func (_m *SSMAPI) GetDefaultPatchBaselineRequest(_a0 *ssm.GetDefaultPatchBaselineInput) (*request.Request, *ssm.GetDefaultPatchBaselineOutput) {
+	ret := _m.Called(_a0)
+
+	var r0 *request.Request
+	if rf, ok := ret.Get(0).(func(*ssm.GetDefaultPatchBaselineInput) *request.Request); ok {
+		r0 = rf(_a0)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*request.Request)
+		}
+	}
+
+	var r1 *ssm.GetDefaultPatchBaselineOutput
+	if rf, ok := ret.Get(1).(func(*ssm.GetDefaultPatchBaselineInput) *ssm.GetDefaultPatchBaselineOutput); ok {
+		r1 = rf(_a0)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*ssm.GetDefaultPatchBaselineOutput)
 		}
 	}
 
@@ -4718,21 +5498,21 @@ func (_m *SSMAPI) GetMaintenanceWindowWithContext(_a0 aws.Context, _a1 *ssm.GetM
 	return r0, r1
 }
 
-// GetParameter provides a mock function with given fields: _a0
-func (_m *SSMAPI) GetParameter(_a0 *ssm.GetParameterInput) (*ssm.GetParameterOutput, error) {
+// GetOpsItem provides a mock function with given fields: _a0
+func (_m *SSMAPI) GetOpsItem(_a0 *ssm.GetOpsItemInput) (*ssm.GetOpsItemOutput, error) {
 	ret := _m.Called(_a0)
 
-	var r0 *ssm.GetParameterOutput
-	if rf, ok := ret.Get(0).(func(*ssm.GetParameterInput) *ssm.GetParameterOutput); ok {
+	var r0 *ssm.GetOpsItemOutput
+	if rf, ok := ret.Get(0).(func(*ssm.GetOpsItemInput) *ssm.GetOpsItemOutput); ok {
 		r0 = rf(_a0)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*ssm.GetParameterOutput)
+			r0 = ret.Get(0).(*ssm.GetOpsItemOutput)
 		}
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(*ssm.GetParameterInput) error); ok {
+	if rf, ok := ret.Get(1).(func(*ssm.GetOpsItemInput) error); ok {
 		r1 = rf(_a0)
 	} else {
 		r1 = ret.Error(1)
@@ -4741,44 +5521,200 @@ func (_m *SSMAPI) GetParameter(_a0 *ssm.GetParameterInput) (*ssm.GetParameterOut
 	return r0, r1
 }
 
-// GetParameterHistory provides a mock function with given fields: _a0
-func (_m *SSMAPI) GetParameterHistory(_a0 *ssm.GetParameterHistoryInput) (*ssm.GetParameterHistoryOutput, error) {
+// GetOpsItemRequest provides a mock function with given fields: _a0
+func (_m *SSMAPI) GetOpsItemRequest(_a0 *ssm.GetOpsItemInput) (*request.Request, *ssm.GetOpsItemOutput) {
 	ret := _m.Called(_a0)
 
-	var r0 *ssm.GetParameterHistoryOutput
-	if rf, ok := ret.Get(0).(func(*ssm.GetParameterHistoryInput) *ssm.GetParameterHistoryOutput); ok {
+	var r0 *request.Request
+	if rf, ok := ret.Get(0).(func(*ssm.GetOpsItemInput) *request.Request); ok {
 		r0 = rf(_a0)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*ssm.GetParameterHistoryOutput)
+			r0 = ret.Get(0).(*request.Request)
 		}
 	}
 
-	var r1 error
-	if rf, ok := ret.Get(1).(func(*ssm.GetParameterHistoryInput) error); ok {
+	var r1 *ssm.GetOpsItemOutput
+	if rf, ok := ret.Get(1).(func(*ssm.GetOpsItemInput) *ssm.GetOpsItemOutput); ok {
 		r1 = rf(_a0)
 	} else {
-		r1 = ret.Error(1)
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*ssm.GetOpsItemOutput)
+		}
 	}
 
 	return r0, r1
 }
 
-// GetParameterHistoryPages provides a mock function with given fields: _a0, _a1
-func (_m *SSMAPI) GetParameterHistoryPages(_a0 *ssm.GetParameterHistoryInput, _a1 func(*ssm.GetParameterHistoryOutput, bool) bool) error {
-	ret := _m.Called(_a0, _a1)
+// GetOpsItemWithContext provides a mock function with given fields: _a0, _a1, _a2
+func (_m *SSMAPI) GetOpsItemWithContext(_a0 aws.Context, _a1 *ssm.GetOpsItemInput, _a2 ...request.Option) (*ssm.GetOpsItemOutput, error) {
+	_va := make([]interface{}, len(_a2))
+	for _i := range _a2 {
+		_va[_i] = _a2[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, _a0, _a1)
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
 
-	var r0 error
-	if rf, ok := ret.Get(0).(func(*ssm.GetParameterHistoryInput, func(*ssm.GetParameterHistoryOutput, bool) bool) error); ok {
-		r0 = rf(_a0, _a1)
+	var r0 *ssm.GetOpsItemOutput
+	if rf, ok := ret.Get(0).(func(aws.Context, *ssm.GetOpsItemInput, ...request.Option) *ssm.GetOpsItemOutput); ok {
+		r0 = rf(_a0, _a1, _a2...)
 	} else {
-		r0 = ret.Error(0)
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*ssm.GetOpsItemOutput)
+		}
 	}
 
-	return r0
-}
-
-// GetParameterHistoryPagesWithContext provides a mock function with given fields: _a0, _a1, _a2, _a3
+	var r1 error
+	if rf, ok := ret.Get(1).(func(aws.Context, *ssm.GetOpsItemInput, ...request.Option) error); ok {
+		r1 = rf(_a0, _a1, _a2...)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetOpsSummary provides a mock function with given fields: _a0
+func (_m *SSMAPI) GetOpsSummary(_a0 *ssm.GetOpsSummaryInput) (*ssm.GetOpsSummaryOutput, error) {
+	ret := _m.Called(_a0)
+
+	var r0 *ssm.GetOpsSummaryOutput
+	if rf, ok := ret.Get(0).(func(*ssm.GetOpsSummaryInput) *ssm.GetOpsSummaryOutput); ok {
+		r0 = rf(_a0)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*ssm.GetOpsSummaryOutput)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(*ssm.GetOpsSummaryInput) error); ok {
+		r1 = rf(_a0)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetOpsSummaryRequest provides a mock function with given fields: _a0
+func (_m *SSMAPI) GetOpsSummaryRequest(_a0 *ssm.GetOpsSummaryInput) (*request.Request, *ssm.GetOpsSummaryOutput) {
+	ret := _m.Called(_a0)
+
+	var r0 *request.Request
+	if rf, ok := ret.Get(0).(func(*ssm.GetOpsSummaryInput) *request.Request); ok {
+		r0 = rf(_a0)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*request.Request)
+		}
+	}
+
+	var r1 *ssm.GetOpsSummaryOutput
+	if rf, ok := ret.Get(1).(func(*ssm.GetOpsSummaryInput) *ssm.GetOpsSummaryOutput); ok {
+		r1 = rf(_a0)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*ssm.GetOpsSummaryOutput)
+		}
+	}
+
+	return r0, r1
+}
+
+// GetOpsSummaryWithContext provides a mock function with given fields: _a0, _a1, _a2
+func (_m *SSMAPI) GetOpsSummaryWithContext(_a0 aws.Context, _a1 *ssm.GetOpsSummaryInput, _a2 ...request.Option) (*ssm.GetOpsSummaryOutput, error) {
+	_va := make([]interface{}, len(_a2))
+	for _i := range _a2 {
+		_va[_i] = _a2[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, _a0, _a1)
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	var r0 *ssm.GetOpsSummaryOutput
+	if rf, ok := ret.Get(0).(func(aws.Context, *ssm.GetOpsSummaryInput, ...request.Option) *ssm.GetOpsSummaryOutput); ok {
+		r0 = rf(_a0, _a1, _a2...)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*ssm.GetOpsSummaryOutput)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(aws.Context, *ssm.GetOpsSummaryInput, ...request.Option) error); ok {
+		r1 = rf(_a0, _a1, _a2...)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetParameter provides a mock function with given fields: _a0
+func (_m *SSMAPI) GetParameter(_a0 *ssm.GetParameterInput) (*ssm.GetParameterOutput, error) {
+	ret := _m.Called(_a0)
+
+	var r0 *ssm.GetParameterOutput
+	if rf, ok := ret.Get(0).(func(*ssm.GetParameterInput) *ssm.GetParameterOutput); ok {
+		r0 = rf(_a0)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*ssm.GetParameterOutput)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(*ssm.GetParameterInput) error); ok {
+		r1 = rf(_a0)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetParameterHistory provides a mock function with given fields: _a0
+func (_m *SSMAPI) GetParameterHistory(_a0 *ssm.GetParameterHistoryInput) (*ssm.GetParameterHistoryOutput, error) {
+	ret := _m.Called(_a0)
+
+	var r0 *ssm.GetParameterHistoryOutput
+	if rf, ok := ret.Get(0).(func(*ssm.GetParameterHistoryInput) *ssm.GetParameterHistoryOutput); ok {
+		r0 = rf(_a0)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*ssm.GetParameterHistoryOutput)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(*ssm.GetParameterHistoryInput) error); ok {
+		r1 = rf(_a0)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetParameterHistoryPages provides a mock function with given fields: _a0, _a1
+func (_m *SSMAPI) GetParameterHistoryPages(_a0 *ssm.GetParameterHistoryInput, _a1 func(*ssm.GetParameterHistoryOutput, bool) bool) error {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*ssm.GetParameterHistoryInput, func(*ssm.GetParameterHistoryOutput, bool) bool) error); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetParameterHistoryPagesWithContext provides a mock function with given fields: _a0, _a1, _a2, _a3
 func (_m *SSMAPI) GetParameterHistoryPagesWithContext(_a0 aws.Context, _a1 *ssm.GetParameterHistoryInput, _a2 func(*ssm.GetParameterHistoryOutput, bool) bool, _a3 ...request.Option) error {
 	_va := make([]interface{}, len(_a3))
 	for _i := range _a3 {
@@ -5256,6 +6192,162 @@ func (_m *SSMAPI) GetPatchBaselineWithContext(_a0 aws.Context, _a1 *ssm.GetPatch
 	return r0, r1
 }
 
+// GetServiceSetting provides a mock function with given fields: _a0
+func (_m *SSMAPI) GetServiceSetting(_a0 *ssm.GetServiceSettingInput) (*ssm.GetServiceSettingOutput, error) {
+	ret := _m.Called(_a0)
+
+	var r0 *ssm.GetServiceSettingOutput
+	if rf, ok := ret.Get(0).(func(*ssm.GetServiceSettingInput) *ssm.GetServiceSettingOutput); ok {
+		r0 = rf(_a0)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*ssm.GetServiceSettingOutput)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(*ssm.GetServiceSettingInput) error); ok {
+		r1 = rf(_a0)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetServiceSettingRequest provides a mock function with given fields: _a0
+func (_m *SSMAPI) GetServiceSettingRequest(_a0 *ssm.GetServiceSettingInput) (*request.Request, *ssm.GetServiceSettingOutput) {
+	ret := _m.Called(_a0)
+
+	var r0 *request.Request
+	if rf, ok := ret.Get(0).(func(*ssm.GetServiceSettingInput) *request.Request); ok {
+		r0 = rf(_a0)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*request.Request)
+		}
+	}
+
+	var r1 *ssm.GetServiceSettingOutput
+	if rf, ok := ret.Get(1).(func(*ssm.GetServiceSettingInput) *ssm.GetServiceSettingOutput); ok {
+		r1 = rf(_a0)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*ssm.GetServiceSettingOutput)
+		}
+	}
+
+	return r0, r1
+}
+
+// GetServiceSettingWithContext provides a mock function with given fields: _a0, _a1, _a2
+func (_m *SSMAPI) GetServiceSettingWithContext(_a0 aws.Context, _a1 *ssm.GetServiceSettingInput, _a2 ...request.Option) (*ssm.GetServiceSettingOutput, error) {
+	_va := make([]interface{}, len(_a2))
+	for _i := range _a2 {
+		_va[_i] = _a2[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, _a0, _a1)
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	var r0 *ssm.GetServiceSettingOutput
+	if rf, ok := ret.Get(0).(func(aws.Context, *ssm.GetServiceSettingInput, ...request.Option) *ssm.GetServiceSettingOutput); ok {
+		r0 = rf(_a0, _a1, _a2...)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*ssm.GetServiceSettingOutput)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(aws.Context, *ssm.GetServiceSettingInput, ...request.Option) error); ok {
+		r1 = rf(_a0, _a1, _a2...)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// LabelParameterVersion provides a mock function with given fields: _a0
+func (_m *SSMAPI) LabelParameterVersion(_a0 *ssm.LabelParameterVersionInput) (*ssm.LabelParameterVersionOutput, error) {
+	ret := _m.Called(_a0)
+
+	var r0 *ssm.LabelParameterVersionOutput
+	if rf, ok := ret.Get(0).(func(*ssm.LabelParameterVersionInput) *ssm.LabelParameterVersionOutput); ok {
+		r0 = rf(_a0)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*ssm.LabelParameterVersionOutput)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(*ssm.LabelParameterVersionInput) error); ok {
+		r1 = rf(_a0)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// LabelParameterVersionRequest provides a mock function with given fields: _a0
+func (_m *SSMAPI) LabelParameterVersionRequest(_a0 *ssm.LabelParameterVersionInput) (*request.Request, *ssm.LabelParameterVersionOutput) {
+	ret := _m.Called(_a0)
+
+	var r0 *request.Request
+	if rf, ok := ret.Get(0).(func(*ssm.LabelParameterVersionInput) *request.Request); ok {
+		r0 = rf(_a0)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*request.Request)
+		}
+	}
+
+	var r1 *ssm.LabelParameterVersionOutput
+	if rf, ok := ret.Get(1).(func(*ssm.LabelParameterVersionInput) *ssm.LabelParameterVersionOutput); ok {
+		r1 = rf(_a0)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*ssm.LabelParameterVersionOutput)
+		}
+	}
+
+	return r0, r1
+}
+
+// LabelParameterVersionWithContext provides a mock function with given fields: _a0, _a1, _a2
+func (_m *SSMAPI) LabelParameterVersionWithContext(_a0 aws.Context, _a1 *ssm.LabelParameterVersionInput, _a2 ...request.Option) (*ssm.LabelParameterVersionOutput, error) {
+	_va := make([]interface{}, len(_a2))
+	for _i := range _a2 {
+		_va[_i] = _a2[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, _a0, _a1)
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	var r0 *ssm.LabelParameterVersionOutput
+	if rf, ok := ret.Get(0).(func(aws.Context, *ssm.LabelParameterVersionInput, ...request.Option) *ssm.LabelParameterVersionOutput); ok {
+		r0 = rf(_a0, _a1, _a2...)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*ssm.LabelParameterVersionOutput)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(aws.Context, *ssm.LabelParameterVersionInput, ...request.Option) error); ok {
+		r1 = rf(_a0, _a1, _a2...)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // ListAssociationVersions provides a mock function with given fields: _a0
 func (_m *SSMAPI) ListAssociationVersions(_a0 *ssm.ListAssociationVersionsInput) (*ssm.ListAssociationVersionsOutput, error) {
 	ret := _m.Called(_a0)
@@ -6937,17 +8029,407 @@ func (_m *SSMAPI) RegisterTaskWithMaintenanceWindowWithContext(_a0 aws.Context,
 	_ca = append(_ca, _va...)
 	ret := _m.Called(_ca...)
 
-	var r0 *ssm.RegisterTaskWithMaintenanceWindowOutput
-	if rf, ok := ret.Get(0).(func(aws.Context, *ssm.RegisterTaskWithMaintenanceWindowInput, ...request.Option) *ssm.RegisterTaskWithMaintenanceWindowOutput); ok {
+	var r0 *ssm.RegisterTaskWithMaintenanceWindowOutput
+	if rf, ok := ret.Get(0).(func(aws.Context, *ssm.RegisterTaskWithMaintenanceWindowInput, ...request.Option) *ssm.RegisterTaskWithMaintenanceWindowOutput); ok {
+		r0 = rf(_a0, _a1, _a2...)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*ssm.RegisterTaskWithMaintenanceWindowOutput)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(aws.Context, *ssm.RegisterTaskWithMaintenanceWindowInput, ...request.Option) error); ok {
+		r1 = rf(_a0, _a1, _a2...)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RemoveTagsFromResource provides a mock function with given fields: _a0
+func (_m *SSMAPI) RemoveTagsFromResource(_a0 *ssm.RemoveTagsFromResourceInput) (*ssm.RemoveTagsFromResourceOutput, error) {
+	ret := _m.Called(_a0)
+
+	var r0 *ssm.RemoveTagsFromResourceOutput
+	if rf, ok := ret.Get(0).(func(*ssm.RemoveTagsFromResourceInput) *ssm.RemoveTagsFromResourceOutput); ok {
+		r0 = rf(_a0)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*ssm.RemoveTagsFromResourceOutput)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(*ssm.RemoveTagsFromResourceInput) error); ok {
+		r1 = rf(_a0)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RemoveTagsFromResourceRequest provides a mock function with given fields: _a0
+func (_m *SSMAPI) RemoveTagsFromResourceRequest(_a0 *ssm.RemoveTagsFromResourceInput) (*request.Request, *ssm.RemoveTagsFromResourceOutput) {
+	ret := _m.Called(_a0)
+
+	var r0 *request.Request
+	if rf, ok := ret.Get(0).(func(*ssm.RemoveTagsFromResourceInput) *request.Request); ok {
+		r0 = rf(_a0)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*request.Request)
+		}
+	}
+
+	var r1 *ssm.RemoveTagsFromResourceOutput
+	if rf, ok := ret.Get(1).(func(*ssm.RemoveTagsFromResourceInput) *ssm.RemoveTagsFromResourceOutput); ok {
+		r1 = rf(_a0)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*ssm.RemoveTagsFromResourceOutput)
+		}
+	}
+
+	return r0, r1
+}
+
+// RemoveTagsFromResourceWithContext provides a mock function with given fields: _a0, _a1, _a2
+func (_m *SSMAPI) RemoveTagsFromResourceWithContext(_a0 aws.Context, _a1 *ssm.RemoveTagsFromResourceInput, _a2 ...request.Option) (*ssm.RemoveTagsFromResourceOutput, error) {
+	_va := make([]interface{}, len(_a2))
+	for _i := range _a2 {
+		_va[_i] = _a2[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, _a0, _a1)
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	var r0 *ssm.RemoveTagsFromResourceOutput
+	if rf, ok := ret.Get(0).(func(aws.Context, *ssm.RemoveTagsFromResourceInput, ...request.Option) *ssm.RemoveTagsFromResourceOutput); ok {
+		r0 = rf(_a0, _a1, _a2...)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*ssm.RemoveTagsFromResourceOutput)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(aws.Context, *ssm.RemoveTagsFromResourceInput, ...request.Option) error); ok {
+		r1 = rf(_a0, _a1, _a2...)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ResetServiceSetting provides a mock function with given fields: _a0
+func (_m *SSMAPI) ResetServiceSetting(_a0 *ssm.ResetServiceSettingInput) (*ssm.ResetServiceSettingOutput, error) {
+	ret := _m.Called(_a0)
+
+	var r0 *ssm.ResetServiceSettingOutput
+	if rf, ok := ret.Get(0).(func(*ssm.ResetServiceSettingInput) *ssm.ResetServiceSettingOutput); ok {
+		r0 = rf(_a0)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*ssm.ResetServiceSettingOutput)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(*ssm.ResetServiceSettingInput) error); ok {
+		r1 = rf(_a0)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ResetServiceSettingRequest provides a mock function with given fields: _a0
+func (_m *SSMAPI) ResetServiceSettingRequest(_a0 *ssm.ResetServiceSettingInput) (*request.Request, *ssm.ResetServiceSettingOutput) {
+	ret := _m.Called(_a0)
+
+	var r0 *request.Request
+	if rf, ok := ret.Get(0).(func(*ssm.ResetServiceSettingInput) *request.Request); ok {
+		r0 = rf(_a0)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*request.Request)
+		}
+	}
+
+	var r1 *ssm.ResetServiceSettingOutput
+	if rf, ok := ret.Get(1).(func(*ssm.ResetServiceSettingInput) *ssm.ResetServiceSettingOutput); ok {
+		r1 = rf(_a0)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*ssm.ResetServiceSettingOutput)
+		}
+	}
+
+	return r0, r1
+}
+
+// ResetServiceSettingWithContext provides a mock function with given fields: _a0, _a1, _a2
+func (_m *SSMAPI) ResetServiceSettingWithContext(_a0 aws.Context, _a1 *ssm.ResetServiceSettingInput, _a2 ...request.Option) (*ssm.ResetServiceSettingOutput, error) {
+	_va := make([]interface{}, len(_a2))
+	for _i := range _a2 {
+		_va[_i] = _a2[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, _a0, _a1)
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	var r0 *ssm.ResetServiceSettingOutput
+	if rf, ok := ret.Get(0).(func(aws.Context, *ssm.ResetServiceSettingInput, ...request.Option) *ssm.ResetServiceSettingOutput); ok {
+		r0 = rf(_a0, _a1, _a2...)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*ssm.ResetServiceSettingOutput)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(aws.Context, *ssm.ResetServiceSettingInput, ...request.Option) error); ok {
+		r1 = rf(_a0, _a1, _a2...)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ResumeSession provides a mock function with given fields: _a0
+func (_m *SSMAPI) ResumeSession(_a0 *ssm.ResumeSessionInput) (*ssm.ResumeSessionOutput, error) {
+	ret := _m.Called(_a0)
+
+	var r0 *ssm.ResumeSessionOutput
+	if rf, ok := ret.Get(0).(func(*ssm.ResumeSessionInput) *ssm.ResumeSessionOutput); ok {
+		r0 = rf(_a0)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*ssm.ResumeSessionOutput)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(*ssm.ResumeSessionInput) error); ok {
+		r1 = rf(_a0)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ResumeSessionRequest provides a mock function with given fields: _a0
+func (_m *SSMAPI) ResumeSessionRequest(_a0 *ssm.ResumeSessionInput) (*request.Request, *ssm.ResumeSessionOutput) {
+	ret := _m.Called(_a0)
+
+	var r0 *request.Request
+	if rf, ok := ret.Get(0).(func(*ssm.ResumeSessionInput) *request.Request); ok {
+		r0 = rf(_a0)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*request.Request)
+		}
+	}
+
+	var r1 *ssm.ResumeSessionOutput
+	if rf, ok := ret.Get(1).(func(*ssm.ResumeSessionInput) *ssm.ResumeSessionOutput); ok {
+		r1 = rf(_a0)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*ssm.ResumeSessionOutput)
+		}
+	}
+
+	return r0, r1
+}
+
+// ResumeSessionWithContext provides a mock function with given fields: _a0, _a1, _a2
+func (_m *SSMAPI) ResumeSessionWithContext(_a0 aws.Context, _a1 *ssm.ResumeSessionInput, _a2 ...request.Option) (*ssm.ResumeSessionOutput, error) {
+	_va := make([]interface{}, len(_a2))
+	for _i := range _a2 {
+		_va[_i] = _a2[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, _a0, _a1)
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	var r0 *ssm.ResumeSessionOutput
+	if rf, ok := ret.Get(0).(func(aws.Context, *ssm.ResumeSessionInput, ...request.Option) *ssm.ResumeSessionOutput); ok {
+		r0 = rf(_a0, _a1, _a2...)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*ssm.ResumeSessionOutput)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(aws.Context, *ssm.ResumeSessionInput, ...request.Option) error); ok {
+		r1 = rf(_a0, _a1, _a2...)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SendAutomationSignal provides a mock function with given fields: _a0
+func (_m *SSMAPI) SendAutomationSignal(_a0 *ssm.SendAutomationSignalInput) (*ssm.SendAutomationSignalOutput, error) {
+	ret := _m.Called(_a0)
+
+	var r0 *ssm.SendAutomationSignalOutput
+	if rf, ok := ret.Get(0).(func(*ssm.SendAutomationSignalInput) *ssm.SendAutomationSignalOutput); ok {
+		r0 = rf(_a0)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*ssm.SendAutomationSignalOutput)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(*ssm.SendAutomationSignalInput) error); ok {
+		r1 = rf(_a0)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SendAutomationSignalRequest provides a mock function with given fields: _a0
+func (_m *SSMAPI) SendAutomationSignalRequest(_a0 *ssm.SendAutomationSignalInput) (*request.Request, *ssm.SendAutomationSignalOutput) {
+	ret := _m.Called(_a0)
+
+	var r0 *request.Request
+	if rf, ok := ret.Get(0).(func(*ssm.SendAutomationSignalInput) *request.Request); ok {
+		r0 = rf(_a0)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*request.Request)
+		}
+	}
+
+	var r1 *ssm.SendAutomationSignalOutput
+	if rf, ok := ret.Get(1).(func(*ssm.SendAutomationSignalInput) *ssm.SendAutomationSignalOutput); ok {
+		r1 = rf(_a0)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*ssm.SendAutomationSignalOutput)
+		}
+	}
+
+	return r0, r1
+}
+
+// SendAutomationSignalWithContext provides a mock function with given fields: _a0, _a1, _a2
+func (_m *SSMAPI) SendAutomationSignalWithContext(_a0 aws.Context, _a1 *ssm.SendAutomationSignalInput, _a2 ...request.Option) (*ssm.SendAutomationSignalOutput, error) {
+	_va := make([]interface{}, len(_a2))
+	for _i := range _a2 {
+		_va[_i] = _a2[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, _a0, _a1)
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	var r0 *ssm.SendAutomationSignalOutput
+	if rf, ok := ret.Get(0).(func(aws.Context, *ssm.SendAutomationSignalInput, ...request.Option) *ssm.SendAutomationSignalOutput); ok {
+		r0 = rf(_a0, _a1, _a2...)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*ssm.SendAutomationSignalOutput)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(aws.Context, *ssm.SendAutomationSignalInput, ...request.Option) error); ok {
+		r1 = rf(_a0, _a1, _a2...)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SendCommand provides a mock function with given fields: _a0
+func (_m *SSMAPI) SendCommand(_a0 *ssm.SendCommandInput) (*ssm.SendCommandOutput, error) {
+	ret := _m.Called(_a0)
+
+	var r0 *ssm.SendCommandOutput
+	if rf, ok := ret.Get(0).(func(*ssm.SendCommandInput) *ssm.SendCommandOutput); ok {
+		r0 = rf(_a0)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*ssm.SendCommandOutput)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(*ssm.SendCommandInput) error); ok {
+		r1 = rf(_a0)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SendCommandRequest provides a mock function with given fields: _a0
+func (_m *SSMAPI) SendCommandRequest(_a0 *ssm.SendCommandInput) (*request.Request, *ssm.SendCommandOutput) {
+	ret := _m.Called(_a0)
+
+	var r0 *request.Request
+	if rf, ok := ret.Get(0).(func(*ssm.SendCommandInput) *request.Request); ok {
+		r0 = rf(_a0)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*request.Request)
+		}
+	}
+
+	var r1 *ssm.SendCommandOutput
+	if rf, ok := ret.Get(1).(func(*ssm.SendCommandInput) *ssm.SendCommandOutput); ok {
+		r1 = rf(_a0)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*ssm.SendCommandOutput)
+		}
+	}
+
+	return r0, r1
+}
+
+// SendCommandWithContext provides a mock function with given fields: _a0, _a1, _a2
+func (_m *SSMAPI) SendCommandWithContext(_a0 aws.Context, _a1 *ssm.SendCommandInput, _a2 ...request.Option) (*ssm.SendCommandOutput, error) {
+	_va := make([]interface{}, len(_a2))
+	for _i := range _a2 {
+		_va[_i] = _a2[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, _a0, _a1)
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	var r0 *ssm.SendCommandOutput
+	if rf, ok := ret.Get(0).(func(aws.Context, *ssm.SendCommandInput, ...request.Option) *ssm.SendCommandOutput); ok {
 		r0 = rf(_a0, _a1, _a2...)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*ssm.RegisterTaskWithMaintenanceWindowOutput)
+			r0 = ret.Get(0).(*ssm.SendCommandOutput)
 		}
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(aws.Context, *ssm.RegisterTaskWithMaintenanceWindowInput, ...request.Option) error); ok {
+	if rf, ok := ret.Get(1).(func(aws.Context, *ssm.SendCommandInput, ...request.Option) error); ok {
 		r1 = rf(_a0, _a1, _a2...)
 	} else {
 		r1 = ret.Error(1)
@@ -6956,21 +8438,21 @@ func (_m *SSMAPI) RegisterTaskWithMaintenanceWindowWithContext(_a0 aws.Context,
 	return r0, r1
 }
 
-// RemoveTagsFromResource provides a mock function with given fields: _a0
-func (_m *SSMAPI) RemoveTagsFromResource(_a0 *ssm.RemoveTagsFromResourceInput) (*ssm.RemoveTagsFromResourceOutput, error) {
+// StartAssociationsOnce provides a mock function with given fields: _a0
+func (_m *SSMAPI) StartAssociationsOnce(_a0 *ssm.StartAssociationsOnceInput) (*ssm.StartAssociationsOnceOutput, error) {
 	ret := _m.Called(_a0)
 
-	var r0 *ssm.RemoveTagsFromResourceOutput
-	if rf, ok := ret.Get(0).(func(*ssm.RemoveTagsFromResourceInput) *ssm.RemoveTagsFromResourceOutput); ok {
+	var r0 *ssm.StartAssociationsOnceOutput
+	if rf, ok := ret.Get(0).(func(*ssm.StartAssociationsOnceInput) *ssm.StartAssociationsOnceOutput); ok {
 		r0 = rf(_a0)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*ssm.RemoveTagsFromResourceOutput)
+			r0 = ret.Get(0).(*ssm.StartAssociationsOnceOutput)
 		}
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(*ssm.RemoveTagsFromResourceInput) error); ok {
+	if rf, ok := ret.Get(1).(func(*ssm.StartAssociationsOnceInput) error); ok {
 		r1 = rf(_a0)
 	} else {
 		r1 = ret.Error(1)
@@ -6979,12 +8461,12 @@ func (_m *SSMAPI) RemoveTagsFromResource(_a0 *ssm.RemoveTagsFromResourceInput) (
 	return r0, r1
 }
 
-// RemoveTagsFromResourceRequest provides a mock function with given fields: _a0
-func (_m *SSMAPI) RemoveTagsFromResourceRequest(_a0 *ssm.RemoveTagsFromResourceInput) (*request.Request, *ssm.RemoveTagsFromResourceOutput) {
+// StartAssociationsOnceRequest provides a mock function with given fields: _a0
+func (_m *SSMAPI) StartAssociationsOnceRequest(_a0 *ssm.StartAssociationsOnceInput) (*request.Request, *ssm.StartAssociationsOnceOutput) {
 	ret := _m.Called(_a0)
 
 	var r0 *request.Request
-	if rf, ok := ret.Get(0).(func(*ssm.RemoveTagsFromResourceInput) *request.Request); ok {
+	if rf, ok := ret.Get(0).(func(*ssm.StartAssociationsOnceInput) *request.Request); ok {
 		r0 = rf(_a0)
 	} else {
 		if ret.Get(0) != nil {
@@ -6992,20 +8474,20 @@ func (_m *SSMAPI) RemoveTagsFromResourceRequest(_a0 *ssm.RemoveTagsFromResourceI
 		}
 	}
 
-	var r1 *ssm.RemoveTagsFromResourceOutput
-	if rf, ok := ret.Get(1).(func(*ssm.RemoveTagsFromResourceInput) *ssm.RemoveTagsFromResourceOutput); ok {
+	var r1 *ssm.StartAssociationsOnceOutput
+	if rf, ok := ret.Get(1).(func(*ssm.StartAssociationsOnceInput) *ssm.StartAssociationsOnceOutput); ok {
 		r1 = rf(_a0)
 	} else {
 		if ret.Get(1) != nil {
-			r1 = ret.Get(1).(*ssm.RemoveTagsFromResourceOutput)
+			r1 = ret.Get(1).(*ssm.StartAssociationsOnceOutput)
 		}
 	}
 
 	return r0, r1
 }
 
-// RemoveTagsFromResourceWithContext provides a mock function with given fields: _a0, _a1, _a2
-func (_m *SSMAPI) RemoveTagsFromResourceWithContext(_a0 aws.Context, _a1 *ssm.RemoveTagsFromResourceInput, _a2 ...request.Option) (*ssm.RemoveTagsFromResourceOutput, error) {
+// StartAssociationsOnceWithContext provides a mock function with given fields: _a0, _a1, _a2
+func (_m *SSMAPI) StartAssociationsOnceWithContext(_a0 aws.Context, _a1 *ssm.StartAssociationsOnceInput, _a2 ...request.Option) (*ssm.StartAssociationsOnceOutput, error) {
 	_va := make([]interface{}, len(_a2))
 	for _i := range _a2 {
 		_va[_i] = _a2[_i]
@@ -7015,17 +8497,17 @@ func (_m *SSMAPI) RemoveTagsFromResourceWithContext(_a0 aws.Context, _a1 *ssm.Re
 	_ca = append(_ca, _va...)
 	ret := _m.Called(_ca...)
 
-	var r0 *ssm.RemoveTagsFromResourceOutput
-	if rf, ok := ret.Get(0).(func(aws.Context, *ssm.RemoveTagsFromResourceInput, ...request.Option) *ssm.RemoveTagsFromResourceOutput); ok {
+	var r0 *ssm.StartAssociationsOnceOutput
+	if rf, ok := ret.Get(0).(func(aws.Context, *ssm.StartAssociationsOnceInput, ...request.Option) *ssm.StartAssociationsOnceOutput); ok {
 		r0 = rf(_a0, _a1, _a2...)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*ssm.RemoveTagsFromResourceOutput)
+			r0 = ret.Get(0).(*ssm.StartAssociationsOnceOutput)
 		}
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(aws.Context, *ssm.RemoveTagsFromResourceInput, ...request.Option) error); ok {
+	if rf, ok := ret.Get(1).(func(aws.Context, *ssm.StartAssociationsOnceInput, ...request.Option) error); ok {
 		r1 = rf(_a0, _a1, _a2...)
 	} else {
 		r1 = ret.Error(1)
@@ -7034,21 +8516,21 @@ func (_m *SSMAPI) RemoveTagsFromResourceWithContext(_a0 aws.Context, _a1 *ssm.Re
 	return r0, r1
 }
 
-// SendAutomationSignal provides a mock function with given fields: _a0
-func (_m *SSMAPI) SendAutomationSignal(_a0 *ssm.SendAutomationSignalInput) (*ssm.SendAutomationSignalOutput, error) {
+// StartAutomationExecution provides a mock function with given fields: _a0
+func (_m *SSMAPI) StartAutomationExecution(_a0 *ssm.StartAutomationExecutionInput) (*ssm.StartAutomationExecutionOutput, error) {
 	ret := _m.Called(_a0)
 
-	var r0 *ssm.SendAutomationSignalOutput
-	if rf, ok := ret.Get(0).(func(*ssm.SendAutomationSignalInput) *ssm.SendAutomationSignalOutput); ok {
+	var r0 *ssm.StartAutomationExecutionOutput
+	if rf, ok := ret.Get(0).(func(*ssm.StartAutomationExecutionInput) *ssm.StartAutomationExecutionOutput); ok {
 		r0 = rf(_a0)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*ssm.SendAutomationSignalOutput)
+			r0 = ret.Get(0).(*ssm.StartAutomationExecutionOutput)
 		}
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(*ssm.SendAutomationSignalInput) error); ok {
+	if rf, ok := ret.Get(1).(func(*ssm.StartAutomationExecutionInput) error); ok {
 		r1 = rf(_a0)
 	} else {
 		r1 = ret.Error(1)
@@ -7057,12 +8539,12 @@ func (_m *SSMAPI) SendAutomationSignal(_a0 *ssm.SendAutomationSignalInput) (*ssm
 	return r0, r1
 }
 
-// SendAutomationSignalRequest provides a mock function with given fields: _a0
-func (_m *SSMAPI) SendAutomationSignalRequest(_a0 *ssm.SendAutomationSignalInput) (*request.Request, *ssm.SendAutomationSignalOutput) {
+// StartAutomationExecutionRequest provides a mock function with given fields: _a0
+func (_m *SSMAPI) StartAutomationExecutionRequest(_a0 *ssm.StartAutomationExecutionInput) (*request.Request, *ssm.StartAutomationExecutionOutput) {
 	ret := _m.Called(_a0)
 
 	var r0 *request.Request
-	if rf, ok := ret.Get(0).(func(*ssm.SendAutomationSignalInput) *request.Request); ok {
+	if rf, ok := ret.Get(0).(func(*ssm.StartAutomationExecutionInput) *request.Request); ok {
 		r0 = rf(_a0)
 	} else {
 		if ret.Get(0) != nil {
@@ -7070,20 +8552,20 @@ func (_m *SSMAPI) SendAutomationSignalRequest(_a0 *ssm.SendAutomationSignalInput
 		}
 	}
 
-	var r1 *ssm.SendAutomationSignalOutput
-	if rf, ok := ret.Get(1).(func(*ssm.SendAutomationSignalInput) *ssm.SendAutomationSignalOutput); ok {
+	var r1 *ssm.StartAutomationExecutionOutput
+	if rf, ok := ret.Get(1).(func(*ssm.StartAutomationExecutionInput) *ssm.StartAutomationExecutionOutput); ok {
 		r1 = rf(_a0)
 	} else {
 		if ret.Get(1) != nil {
-			r1 = ret.Get(1).(*ssm.SendAutomationSignalOutput)
+			r1 = ret.Get(1).(*ssm.StartAutomationExecutionOutput)
 		}
 	}
 
 	return r0, r1
 }
 
-// SendAutomationSignalWithContext provides a mock function with given fields: _a0, _a1, _a2
-func (_m *SSMAPI) SendAutomationSignalWithContext(_a0 aws.Context, _a1 *ssm.SendAutomationSignalInput, _a2 ...request.Option) (*ssm.SendAutomationSignalOutput, error) {
+// StartAutomationExecutionWithContext provides a mock function with given fields: _a0, _a1, _a2
+func (_m *SSMAPI) StartAutomationExecutionWithContext(_a0 aws.Context, _a1 *ssm.StartAutomationExecutionInput, _a2 ...request.Option) (*ssm.StartAutomationExecutionOutput, error) {
 	_va := make([]interface{}, len(_a2))
 	for _i := range _a2 {
 		_va[_i] = _a2[_i]
@@ -7093,17 +8575,17 @@ func (_m *SSMAPI) SendAutomationSignalWithContext(_a0 aws.Context, _a1 *ssm.Send
 	_ca = append(_ca, _va...)
 	ret := _m.Called(_ca...)
 
-	var r0 *ssm.SendAutomationSignalOutput
-	if rf, ok := ret.Get(0).(func(aws.Context, *ssm.SendAutomationSignalInput, ...request.Option) *ssm.SendAutomationSignalOutput); ok {
+	var r0 *ssm.StartAutomationExecutionOutput
+	if rf, ok := ret.Get(0).(func(aws.Context, *ssm.StartAutomationExecutionInput, ...request.Option) *ssm.StartAutomationExecutionOutput); ok {
 		r0 = rf(_a0, _a1, _a2...)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*ssm.SendAutomationSignalOutput)
+			r0 = ret.Get(0).(*ssm.StartAutomationExecutionOutput)
 		}
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(aws.Context, *ssm.SendAutomationSignalInput, ...request.Option) error); ok {
+	if rf, ok := ret.Get(1).(func(aws.Context, *ssm.StartAutomationExecutionInput, ...request.Option) error); ok {
 		r1 = rf(_a0, _a1, _a2...)
 	} else {
 		r1 = ret.Error(1)
@@ -7112,21 +8594,21 @@ func (_m *SSMAPI) SendAutomationSignalWithContext(_a0 aws.Context, _a1 *ssm.Send
 	return r0, r1
 }
 
-// SendCommand provides a mock function with given fields: _a0
-func (_m *SSMAPI) SendCommand(_a0 *ssm.SendCommandInput) (*ssm.SendCommandOutput, error) {
+// StartSession provides a mock function with given fields: _a0
+func (_m *SSMAPI) StartSession(_a0 *ssm.StartSessionInput) (*ssm.StartSessionOutput, error) {
 	ret := _m.Called(_a0)
 
-	var r0 *ssm.SendCommandOutput
-	if rf, ok := ret.Get(0).(func(*ssm.SendCommandInput) *ssm.SendCommandOutput); ok {
+	var r0 *ssm.StartSessionOutput
+	if rf, ok := ret.Get(0).(func(*ssm.StartSessionInput) *ssm.StartSessionOutput); ok {
 		r0 = rf(_a0)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*ssm.SendCommandOutput)
+			r0 = ret.Get(0).(*ssm.StartSessionOutput)
 		}
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(*ssm.SendCommandInput) error); ok {
+	if rf, ok := ret.Get(1).(func(*ssm.StartSessionInput) error); ok {
 		r1 = rf(_a0)
 	} else {
 		r1 = ret.Error(1)
@@ -7135,12 +8617,12 @@ func (_m *SSMAPI) SendCommand(_a0 *ssm.SendCommandInput) (*ssm.SendCommandOutput
 	return r0, r1
 }
 
-// SendCommandRequest provides a mock function with given fields: _a0
-func (_m *SSMAPI) SendCommandRequest(_a0 *ssm.SendCommandInput) (*request.Request, *ssm.SendCommandOutput) {
+// StartSessionRequest provides a mock function with given fields: _a0
+func (_m *SSMAPI) StartSessionRequest(_a0 *ssm.StartSessionInput) (*request.Request, *ssm.StartSessionOutput) {
 	ret := _m.Called(_a0)
 
 	var r0 *request.Request
-	if rf, ok := ret.Get(0).(func(*ssm.SendCommandInput) *request.Request); ok {
+	if rf, ok := ret.Get(0).(func(*ssm.StartSessionInput) *request.Request); ok {
 		r0 = rf(_a0)
 	} else {
 		if ret.Get(0) != nil {
@@ -7148,20 +8630,20 @@ func (_m *SSMAPI) SendCommandRequest(_a0 *ssm.SendCommandInput) (*request.Reques
 		}
 	}
 
-	var r1 *ssm.SendCommandOutput
-	if rf, ok := ret.Get(1).(func(*ssm.SendCommandInput) *ssm.SendCommandOutput); ok {
+	var r1 *ssm.StartSessionOutput
+	if rf, ok := ret.Get(1).(func(*ssm.StartSessionInput) *ssm.StartSessionOutput); ok {
 		r1 = rf(_a0)
 	} else {
 		if ret.Get(1) != nil {
-			r1 = ret.Get(1).(*ssm.SendCommandOutput)
+			r1 = ret.Get(1).(*ssm.StartSessionOutput)
 		}
 	}
 
 	return r0, r1
 }
 
-// SendCommandWithContext provides a mock function with given fields: _a0, _a1, _a2
-func (_m *SSMAPI) SendCommandWithContext(_a0 aws.Context, _a1 *ssm.SendCommandInput, _a2 ...request.Option) (*ssm.SendCommandOutput, error) {
+// StartSessionWithContext provides a mock function with given fields: _a0, _a1, _a2
+func (_m *SSMAPI) StartSessionWithContext(_a0 aws.Context, _a1 *ssm.StartSessionInput, _a2 ...request.Option) (*ssm.StartSessionOutput, error) {
 	_va := make([]interface{}, len(_a2))
 	for _i := range _a2 {
 		_va[_i] = _a2[_i]
@@ -7171,17 +8653,17 @@ func (_m *SSMAPI) SendCommandWithContext(_a0 aws.Context, _a1 *ssm.SendCommandIn
 	_ca = append(_ca, _va...)
 	ret := _m.Called(_ca...)
 
-	var r0 *ssm.SendCommandOutput
-	if rf, ok := ret.Get(0).(func(aws.Context, *ssm.SendCommandInput, ...request.Option) *ssm.SendCommandOutput); ok {
+	var r0 *ssm.StartSessionOutput
+	if rf, ok := ret.Get(0).(func(aws.Context, *ssm.StartSessionInput, ...request.Option) *ssm.StartSessionOutput); ok {
 		r0 = rf(_a0, _a1, _a2...)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*ssm.SendCommandOutput)
+			r0 = ret.Get(0).(*ssm.StartSessionOutput)
 		}
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(aws.Context, *ssm.SendCommandInput, ...request.Option) error); ok {
+	if rf, ok := ret.Get(1).(func(aws.Context, *ssm.StartSessionInput, ...request.Option) error); ok {
 		r1 = rf(_a0, _a1, _a2...)
 	} else {
 		r1 = ret.Error(1)
@@ -7190,21 +8672,21 @@ func (_m *SSMAPI) SendCommandWithContext(_a0 aws.Context, _a1 *ssm.SendCommandIn
 	return r0, r1
 }
 
-// StartAutomationExecution provides a mock function with given fields: _a0
-func (_m *SSMAPI) StartAutomationExecution(_a0 *ssm.StartAutomationExecutionInput) (*ssm.StartAutomationExecutionOutput, error) {
+// StopAutomationExecution provides a mock function with given fields: _a0
+func (_m *SSMAPI) StopAutomationExecution(_a0 *ssm.StopAutomationExecutionInput) (*ssm.StopAutomationExecutionOutput, error) {
 	ret := _m.Called(_a0)
 
-	var r0 *ssm.StartAutomationExecutionOutput
-	if rf, ok := ret.Get(0).(func(*ssm.StartAutomationExecutionInput) *ssm.StartAutomationExecutionOutput); ok {
+	var r0 *ssm.StopAutomationExecutionOutput
+	if rf, ok := ret.Get(0).(func(*ssm.StopAutomationExecutionInput) *ssm.StopAutomationExecutionOutput); ok {
 		r0 = rf(_a0)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*ssm.StartAutomationExecutionOutput)
+			r0 = ret.Get(0).(*ssm.StopAutomationExecutionOutput)
 		}
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(*ssm.StartAutomationExecutionInput) error); ok {
+	if rf, ok := ret.Get(1).(func(*ssm.StopAutomationExecutionInput) error); ok {
 		r1 = rf(_a0)
 	} else {
 		r1 = ret.Error(1)
@@ -7213,12 +8695,12 @@ func (_m *SSMAPI) StartAutomationExecution(_a0 *ssm.StartAutomationExecutionInpu
 	return r0, r1
 }
 
-// StartAutomationExecutionRequest provides a mock function with given fields: _a0
-func (_m *SSMAPI) StartAutomationExecutionRequest(_a0 *ssm.StartAutomationExecutionInput) (*request.Request, *ssm.StartAutomationExecutionOutput) {
+// StopAutomationExecutionRequest provides a mock function with given fields: _a0
+func (_m *SSMAPI) StopAutomationExecutionRequest(_a0 *ssm.StopAutomationExecutionInput) (*request.Request, *ssm.StopAutomationExecutionOutput) {
 	ret := _m.Called(_a0)
 
 	var r0 *request.Request
-	if rf, ok := ret.Get(0).(func(*ssm.StartAutomationExecutionInput) *request.Request); ok {
+	if rf, ok := ret.Get(0).(func(*ssm.StopAutomationExecutionInput) *request.Request); ok {
 		r0 = rf(_a0)
 	} else {
 		if ret.Get(0) != nil {
@@ -7226,20 +8708,20 @@ func (_m *SSMAPI) StartAutomationExecutionRequest(_a0 *ssm.StartAutomationExecut
 		}
 	}
 
-	var r1 *ssm.StartAutomationExecutionOutput
-	if rf, ok := ret.Get(1).(func(*ssm.StartAutomationExecutionInput) *ssm.StartAutomationExecutionOutput); ok {
+	var r1 *ssm.StopAutomationExecutionOutput
+	if rf, ok := ret.Get(1).(func(*ssm.StopAutomationExecutionInput) *ssm.StopAutomationExecutionOutput); ok {
 		r1 = rf(_a0)
 	} else {
 		if ret.Get(1) != nil {
-			r1 = ret.Get(1).(*ssm.StartAutomationExecutionOutput)
+			r1 = ret.Get(1).(*ssm.StopAutomationExecutionOutput)
 		}
 	}
 
 	return r0, r1
 }
 
-// StartAutomationExecutionWithContext provides a mock function with given fields: _a0, _a1, _a2
-func (_m *SSMAPI) StartAutomationExecutionWithContext(_a0 aws.Context, _a1 *ssm.StartAutomationExecutionInput, _a2 ...request.Option) (*ssm.StartAutomationExecutionOutput, error) {
+// StopAutomationExecutionWithContext provides a mock function with given fields: _a0, _a1, _a2
+func (_m *SSMAPI) StopAutomationExecutionWithContext(_a0 aws.Context, _a1 *ssm.StopAutomationExecutionInput, _a2 ...request.Option) (*ssm.StopAutomationExecutionOutput, error) {
 	_va := make([]interface{}, len(_a2))
 	for _i := range _a2 {
 		_va[_i] = _a2[_i]
@@ -7249,17 +8731,17 @@ func (_m *SSMAPI) StartAutomationExecutionWithContext(_a0 aws.Context, _a1 *ssm.
 	_ca = append(_ca, _va...)
 	ret := _m.Called(_ca...)
 
-	var r0 *ssm.StartAutomationExecutionOutput
-	if rf, ok := ret.Get(0).(func(aws.Context, *ssm.StartAutomationExecutionInput, ...request.Option) *ssm.StartAutomationExecutionOutput); ok {
+	var r0 *ssm.StopAutomationExecutionOutput
+	if rf, ok := ret.Get(0).(func(aws.Context, *ssm.StopAutomationExecutionInput, ...request.Option) *ssm.StopAutomationExecutionOutput); ok {
 		r0 = rf(_a0, _a1, _a2...)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*ssm.StartAutomationExecutionOutput)
+			r0 = ret.Get(0).(*ssm.StopAutomationExecutionOutput)
 		}
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(aws.Context, *ssm.StartAutomationExecutionInput, ...request.Option) error); ok {
+	if rf, ok := ret.Get(1).(func(aws.Context, *ssm.StopAutomationExecutionInput, ...request.Option) error); ok {
 		r1 = rf(_a0, _a1, _a2...)
 	} else {
 		r1 = ret.Error(1)
@@ -7268,21 +8750,21 @@ func (_m *SSMAPI) StartAutomationExecutionWithContext(_a0 aws.Context, _a1 *ssm.
 	return r0, r1
 }
 
-// StopAutomationExecution provides a mock function with given fields: _a0
-func (_m *SSMAPI) StopAutomationExecution(_a0 *ssm.StopAutomationExecutionInput) (*ssm.StopAutomationExecutionOutput, error) {
+// TerminateSession provides a mock function with given fields: _a0
+func (_m *SSMAPI) TerminateSession(_a0 *ssm.TerminateSessionInput) (*ssm.TerminateSessionOutput, error) {
 	ret := _m.Called(_a0)
 
-	var r0 *ssm.StopAutomationExecutionOutput
-	if rf, ok := ret.Get(0).(func(*ssm.StopAutomationExecutionInput) *ssm.StopAutomationExecutionOutput); ok {
+	var r0 *ssm.TerminateSessionOutput
+	if rf, ok := ret.Get(0).(func(*ssm.TerminateSessionInput) *ssm.TerminateSessionOutput); ok {
 		r0 = rf(_a0)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*ssm.StopAutomationExecutionOutput)
+			r0 = ret.Get(0).(*ssm.TerminateSessionOutput)
 		}
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(*ssm.StopAutomationExecutionInput) error); ok {
+	if rf, ok := ret.Get(1).(func(*ssm.TerminateSessionInput) error); ok {
 		r1 = rf(_a0)
 	} else {
 		r1 = ret.Error(1)
@@ -7291,12 +8773,12 @@ func (_m *SSMAPI) StopAutomationExecution(_a0 *ssm.StopAutomationExecutionInput)
 	return r0, r1
 }
 
-// StopAutomationExecutionRequest provides a mock function with given fields: _a0
-func (_m *SSMAPI) StopAutomationExecutionRequest(_a0 *ssm.StopAutomationExecutionInput) (*request.Request, *ssm.StopAutomationExecutionOutput) {
+// TerminateSessionRequest provides a mock function with given fields: _a0
+func (_m *SSMAPI) TerminateSessionRequest(_a0 *ssm.TerminateSessionInput) (*request.Request, *ssm.TerminateSessionOutput) {
 	ret := _m.Called(_a0)
 
 	var r0 *request.Request
-	if rf, ok := ret.Get(0).(func(*ssm.StopAutomationExecutionInput) *request.Request); ok {
+	if rf, ok := ret.Get(0).(func(*ssm.TerminateSessionInput) *request.Request); ok {
 		r0 = rf(_a0)
 	} else {
 		if ret.Get(0) != nil {
@@ -7304,20 +8786,20 @@ func (_m *SSMAPI) StopAutomationExecutionRequest(_a0 *ssm.StopAutomationExecutio
 		}
 	}
 
-	var r1 *ssm.StopAutomationExecutionOutput
-	if rf, ok := ret.Get(1).(func(*ssm.StopAutomationExecutionInput) *ssm.StopAutomationExecutionOutput); ok {
+	var r1 *ssm.TerminateSessionOutput
+	if rf, ok := ret.Get(1).(func(*ssm.TerminateSessionInput) *ssm.TerminateSessionOutput); ok {
 		r1 = rf(_a0)
 	} else {
 		if ret.Get(1) != nil {
-			r1 = ret.Get(1).(*ssm.StopAutomationExecutionOutput)
+			r1 = ret.Get(1).(*ssm.TerminateSessionOutput)
 		}
 	}
 
 	return r0, r1
 }
 
-// StopAutomationExecutionWithContext provides a mock function with given fields: _a0, _a1, _a2
-func (_m *SSMAPI) StopAutomationExecutionWithContext(_a0 aws.Context, _a1 *ssm.StopAutomationExecutionInput, _a2 ...request.Option) (*ssm.StopAutomationExecutionOutput, error) {
+// TerminateSessionWithContext provides a mock function with given fields: _a0, _a1, _a2
+func (_m *SSMAPI) TerminateSessionWithContext(_a0 aws.Context, _a1 *ssm.TerminateSessionInput, _a2 ...request.Option) (*ssm.TerminateSessionOutput, error) {
 	_va := make([]interface{}, len(_a2))
 	for _i := range _a2 {
 		_va[_i] = _a2[_i]
@@ -7327,17 +8809,17 @@ func (_m *SSMAPI) StopAutomationExecutionWithContext(_a0 aws.Context, _a1 *ssm.S
 	_ca = append(_ca, _va...)
 	ret := _m.Called(_ca...)
 
-	var r0 *ssm.StopAutomationExecutionOutput
-	if rf, ok := ret.Get(0).(func(aws.Context, *ssm.StopAutomationExecutionInput, ...request.Option) *ssm.StopAutomationExecutionOutput); ok {
+	var r0 *ssm.TerminateSessionOutput
+	if rf, ok := ret.Get(0).(func(aws.Context, *ssm.TerminateSessionInput, ...request.Option) *ssm.TerminateSessionOutput); ok {
 		r0 = rf(_a0, _a1, _a2...)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*ssm.StopAutomationExecutionOutput)
+			r0 = ret.Get(0).(*ssm.TerminateSessionOutput)
 		}
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(aws.Context, *ssm.StopAutomationExecutionInput, ...request.Option) error); ok {
+	if rf, ok := ret.Get(1).(func(aws.Context, *ssm.TerminateSessionInput, ...request.Option) error); ok {
 		r1 = rf(_a0, _a1, _a2...)
 	} else {
 		r1 = ret.Error(1)
@@ -7970,6 +9452,84 @@ func (_m *SSMAPI) UpdateManagedInstanceRoleWithContext(_a0 aws.Context, _a1 *ssm
 	return r0, r1
 }
 
+// UpdateOpsItem provides a mock function with given fields: _a0
+func (_m *SSMAPI) UpdateOpsItem(_a0 *ssm.UpdateOpsItemInput) (*ssm.UpdateOpsItemOutput, error) {
+	ret := _m.Called(_a0)
+
+	var r0 *ssm.UpdateOpsItemOutput
+	if rf, ok := ret.Get(0).(func(*ssm.UpdateOpsItemInput) *ssm.UpdateOpsItemOutput); ok {
+		r0 = rf(_a0)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*ssm.UpdateOpsItemOutput)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(*ssm.UpdateOpsItemInput) error); ok {
+		r1 = rf(_a0)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdateOpsItemRequest provides a mock function with given fields: _a0
+func (_m *SSMAPI) UpdateOpsItemRequest(_a0 *ssm.UpdateOpsItemInput) (*request.Request, *ssm.UpdateOpsItemOutput) {
+	ret := _m.Called(_a0)
+
+	var r0 *request.Request
+	if rf, ok := ret.Get(0).(func(*ssm.UpdateOpsItemInput) *request.Request); ok {
+		r0 = rf(_a0)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*request.Request)
+		}
+	}
+
+	var r1 *ssm.UpdateOpsItemOutput
+	if rf, ok := ret.Get(1).(func(*ssm.UpdateOpsItemInput) *ssm.UpdateOpsItemOutput); ok {
+		r1 = rf(_a0)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*ssm.UpdateOpsItemOutput)
+		}
+	}
+
+	return r0, r1
+}
+
+// UpdateOpsItemWithContext provides a mock function with given fields: _a0, _a1, _a2
+func (_m *SSMAPI) UpdateOpsItemWithContext(_a0 aws.Context, _a1 *ssm.UpdateOpsItemInput, _a2 ...request.Option) (*ssm.UpdateOpsItemOutput, error) {
+	_va := make([]interface{}, len(_a2))
+	for _i := range _a2 {
+		_va[_i] = _a2[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, _a0, _a1)
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	var r0 *ssm.UpdateOpsItemOutput
+	if rf, ok := ret.Get(0).(func(aws.Context, *ssm.UpdateOpsItemInput, ...request.Option) *ssm.UpdateOpsItemOutput); ok {
+		r0 = rf(_a0, _a1, _a2...)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*ssm.UpdateOpsItemOutput)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(aws.Context, *ssm.UpdateOpsItemInput, ...request.Option) error); ok {
+		r1 = rf(_a0, _a1, _a2...)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // UpdatePatchBaseline provides a mock function with given fields: _a0
 func (_m *SSMAPI) UpdatePatchBaseline(_a0 *ssm.UpdatePatchBaselineInput) (*ssm.UpdatePatchBaselineOutput, error) {
 	ret := _m.Called(_a0)
@@ -8047,3 +9607,81 @@ func (_m *SSMAPI) UpdatePatchBaselineWithContext(_a0 aws.Context, _a1 *ssm.Updat
 
 	return r0, r1
 }
+
+// UpdateServiceSetting provides a mock function with given fields: _a0
+func (_m *SSMAPI) UpdateServiceSetting(_a0 *ssm.UpdateServiceSettingInput) (*ssm.UpdateServiceSettingOutput, error) {
+	ret := _m.Called(_a0)
+
+	var r0 *ssm.UpdateServiceSettingOutput
+	if rf, ok := ret.Get(0).(func(*ssm.UpdateServiceSettingInput) *ssm.UpdateServiceSettingOutput); ok {
+		r0 = rf(_a0)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*ssm.UpdateServiceSettingOutput)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(*ssm.UpdateServiceSettingInput) error); ok {
+		r1 = rf(_a0)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdateServiceSettingRequest provides a mock function with given fields: _a0
+func (_m *SSMAPI) UpdateServiceSettingRequest(_a0 *ssm.UpdateServiceSettingInput) (*request.Request, *ssm.UpdateServiceSettingOutput) {
+	ret := _m.Called(_a0)
+
+	var r0 *request.Request
+	if rf, ok := ret.Get(0).(func(*ssm.UpdateServiceSettingInput) *request.Request); ok {
+		r0 = rf(_a0)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*request.Request)
+		}
+	}
+
+	var r1 *ssm.UpdateServiceSettingOutput
+	if rf, ok := ret.Get(1).(func(*ssm.UpdateServiceSettingInput) *ssm.UpdateServiceSettingOutput); ok {
+		r1 = rf(_a0)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*ssm.UpdateServiceSettingOutput)
+		}
+	}
+
+	return r0, r1
+}
+
+// UpdateServiceSettingWithContext provides a mock function with given fields: _a0, _a1, _a2
+func (_m *SSMAPI) UpdateServiceSettingWithContext(_a0 aws.Context, _a1 *ssm.UpdateServiceSettingInput, _a2 ...request.Option) (*ssm.UpdateServiceSettingOutput, error) {
+	_va := make([]interface{}, len(_a2))
+	for _i := range _a2 {
+		_va[_i] = _a2[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, _a0, _a1)
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	var r0 *ssm.UpdateServiceSettingOutput
+	if rf, ok := ret.Get(0).(func(aws.Context, *ssm.UpdateServiceSettingInput, ...request.Option) *ssm.UpdateServiceSettingOutput); ok {
+		r0 = rf(_a0, _a1, _a2...)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*ssm.UpdateServiceSettingOutput)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(aws.Context, *ssm.UpdateServiceSettingInput, ...request.Option) error); ok {
+		r1 = rf(_a0, _a1, _a2...)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
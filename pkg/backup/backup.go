@@ -0,0 +1,250 @@
+package backup
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/b-b3rn4rd/json2ssm/pkg/storage"
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	formatVersion  = 1
+	manifestFile   = "manifest.json"
+	parametersFile = "parameters.json"
+)
+
+var ErrPassphraseRequired = errors.New("archive contains encrypted values, passphrase is required")
+
+type Manifest struct {
+	Version int       `json:"version"`
+	Path    string    `json:"path"`
+	Created time.Time `json:"created"`
+	Count   int       `json:"count"`
+	Salt    string    `json:"salt,omitempty"`
+}
+
+type record struct {
+	*storage.Parameter
+	Encrypted bool `json:"encrypted,omitempty"`
+}
+
+// Write stores parameters taken from the given path as a gzipped tar archive,
+// secure string values are encrypted when a passphrase is specified.
+func Write(w io.Writer, path string, params []*storage.Parameter, passphrase string) error {
+	m := Manifest{
+		Version: formatVersion,
+		Path:    path,
+		Created: time.Now().UTC(),
+		Count:   len(params),
+	}
+
+	var aead cipher.AEAD
+	if passphrase != "" {
+		salt := make([]byte, 16)
+		if _, err := rand.Read(salt); err != nil {
+			return err
+		}
+
+		var err error
+		aead, err = newAEAD(passphrase, salt)
+		if err != nil {
+			return err
+		}
+
+		m.Salt = base64.StdEncoding.EncodeToString(salt)
+	}
+
+	records := make([]record, 0, len(params))
+	for _, p := range params {
+		r := record{Parameter: p}
+
+		if aead != nil && p.Type == ssm.ParameterTypeSecureString {
+			c := *p
+			value, err := seal(aead, p.Value)
+			if err != nil {
+				return err
+			}
+
+			c.Value = value
+			r = record{Parameter: &c, Encrypted: true}
+		}
+
+		records = append(records, r)
+	}
+
+	manifest, err := json.MarshalIndent(m, "", " ")
+	if err != nil {
+		return err
+	}
+
+	parameters, err := json.MarshalIndent(records, "", " ")
+	if err != nil {
+		return err
+	}
+
+	gw := gzip.NewWriter(w)
+	tw := tar.NewWriter(gw)
+
+	for _, f := range []struct {
+		name string
+		body []byte
+	}{{manifestFile, manifest}, {parametersFile, parameters}} {
+		err = tw.WriteHeader(&tar.Header{
+			Name:    f.name,
+			Mode:    0600,
+			Size:    int64(len(f.body)),
+			ModTime: m.Created,
+		})
+		if err != nil {
+			return err
+		}
+
+		if _, err = tw.Write(f.body); err != nil {
+			return err
+		}
+	}
+
+	if err = tw.Close(); err != nil {
+		return err
+	}
+
+	return gw.Close()
+}
+
+// Read loads the manifest and parameters from an archive created by Write,
+// decrypting secure string values with the given passphrase.
+func Read(r io.Reader, passphrase string) (*Manifest, []*storage.Parameter, error) {
+	gr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer gr.Close()
+
+	var m *Manifest
+	var records []record
+
+	tr := tar.NewReader(gr)
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+
+		body, err := ioutil.ReadAll(tr)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		switch h.Name {
+		case manifestFile:
+			m = &Manifest{}
+			err = json.Unmarshal(body, m)
+		case parametersFile:
+			err = json.Unmarshal(body, &records)
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("can't parse %s: %s", h.Name, err)
+		}
+	}
+
+	if m == nil {
+		return nil, nil, fmt.Errorf("%s is missing", manifestFile)
+	}
+
+	if m.Version != formatVersion {
+		return nil, nil, fmt.Errorf("unsupported archive version %d", m.Version)
+	}
+
+	var aead cipher.AEAD
+	if m.Salt != "" {
+		if passphrase == "" {
+			return nil, nil, ErrPassphraseRequired
+		}
+
+		salt, err := base64.StdEncoding.DecodeString(m.Salt)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		aead, err = newAEAD(passphrase, salt)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	params := make([]*storage.Parameter, 0, len(records))
+	for _, r := range records {
+		if r.Encrypted {
+			if aead == nil {
+				return nil, nil, ErrPassphraseRequired
+			}
+
+			value, err := open(aead, r.Value)
+			if err != nil {
+				return nil, nil, fmt.Errorf("can't decrypt %s, wrong passphrase?", r.Name)
+			}
+
+			r.Value = value
+		}
+
+		params = append(params, r.Parameter)
+	}
+
+	return m, params, nil
+}
+
+func newAEAD(passphrase string, salt []byte) (cipher.AEAD, error) {
+	key, err := scrypt.Key([]byte(passphrase), salt, 1<<15, 8, 1, 32)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+func seal(aead cipher.AEAD, value string) (string, error) {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(aead.Seal(nonce, nonce, []byte(value), nil)), nil
+}
+
+func open(aead cipher.AEAD, value string) (string, error) {
+	raw, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return "", err
+	}
+
+	if len(raw) < aead.NonceSize() {
+		return "", errors.New("ciphertext is too short")
+	}
+
+	plain, err := aead.Open(nil, raw[:aead.NonceSize()], raw[aead.NonceSize():], nil)
+	if err != nil {
+		return "", err
+	}
+
+	return string(plain), nil
+}
@@ -0,0 +1,78 @@
+package backup_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/b-b3rn4rd/json2ssm/pkg/backup"
+	"github.com/b-b3rn4rd/json2ssm/pkg/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteRead(t *testing.T) {
+	params := []*storage.Parameter{
+		{
+			Name:        "/app/db/host",
+			Value:       "localhost",
+			Type:        ssm.ParameterTypeString,
+			Description: "database host",
+			Tier:        ssm.ParameterTierStandard,
+			Tags:        map[string]string{"type": "string"},
+		},
+		{
+			Name:     "/app/db/password",
+			Value:    "secret",
+			Type:     ssm.ParameterTypeSecureString,
+			KeyID:    "alias/aws/ssm",
+			Tier:     ssm.ParameterTierAdvanced,
+			Policies: []string{`{"Type":"ExpirationNotification","Version":"1.0","Attributes":{"Before":"15","Unit":"Days"}}`},
+		},
+	}
+
+	tests := map[string]struct {
+		passphrase string
+		read       string
+		err        error
+	}{
+		"plain": {},
+		"encrypted": {
+			passphrase: "correct horse",
+			read:       "correct horse",
+		},
+		"missing passphrase": {
+			passphrase: "correct horse",
+			err:        backup.ErrPassphraseRequired,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var b bytes.Buffer
+			assert.NoError(t, backup.Write(&b, "/app", params, test.passphrase))
+
+			m, r, err := backup.Read(&b, test.read)
+			if test.err != nil {
+				assert.Equal(t, test.err, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, "/app", m.Path)
+			assert.Equal(t, 2, m.Count)
+			assert.Equal(t, params, r)
+		})
+	}
+}
+
+func TestReadWrongPassphrase(t *testing.T) {
+	params := []*storage.Parameter{
+		{Name: "/app/db/password", Value: "secret", Type: ssm.ParameterTypeSecureString},
+	}
+
+	var b bytes.Buffer
+	assert.NoError(t, backup.Write(&b, "/app", params, "correct horse"))
+
+	_, _, err := backup.Read(&b, "battery staple")
+	assert.Error(t, err)
+}
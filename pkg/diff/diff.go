@@ -0,0 +1,78 @@
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+)
+
+const (
+	Added   = "+"
+	Removed = "-"
+	Changed = "~"
+)
+
+const mask = "******"
+
+type Change struct {
+	Key  string
+	Kind string
+	Old  interface{}
+	New  interface{}
+}
+
+// Compare returns changes required to turn the left flattened document into
+// the right one, sorted by key.
+func Compare(left, right map[string]interface{}) []Change {
+	var changes []Change
+
+	for k, v := range left {
+		nv, ok := right[k]
+		if !ok {
+			changes = append(changes, Change{Key: k, Kind: Removed, Old: v})
+			continue
+		}
+
+		if !reflect.DeepEqual(v, nv) {
+			changes = append(changes, Change{Key: k, Kind: Changed, Old: v, New: nv})
+		}
+	}
+
+	for k, v := range right {
+		if _, ok := left[k]; !ok {
+			changes = append(changes, Change{Key: k, Kind: Added, New: v})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Key < changes[j].Key
+	})
+
+	return changes
+}
+
+// Print writes changes in a line based format, values of keys in the masked
+// set are replaced with asterisks.
+func Print(w io.Writer, changes []Change, masked map[string]bool) {
+	format := func(key string, v interface{}) string {
+		if masked[key] {
+			return mask
+		}
+
+		raw, _ := json.Marshal(v)
+		return string(raw)
+	}
+
+	for _, c := range changes {
+		switch c.Kind {
+		case Added:
+			fmt.Fprintf(w, "%s %s: %s\n", c.Kind, c.Key, format(c.Key, c.New))
+		case Removed:
+			fmt.Fprintf(w, "%s %s: %s\n", c.Kind, c.Key, format(c.Key, c.Old))
+		case Changed:
+			fmt.Fprintf(w, "%s %s: %s -> %s\n", c.Kind, c.Key, format(c.Key, c.Old), format(c.Key, c.New))
+		}
+	}
+}
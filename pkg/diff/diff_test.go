@@ -0,0 +1,69 @@
+package diff_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/b-b3rn4rd/json2ssm/pkg/diff"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompare(t *testing.T) {
+	tests := map[string]struct {
+		left     map[string]interface{}
+		right    map[string]interface{}
+		response []diff.Change
+	}{
+		"equal": {
+			left:  map[string]interface{}{"name": "bernard", "code": float64(3000)},
+			right: map[string]interface{}{"name": "bernard", "code": float64(3000)},
+		},
+		"added": {
+			left:  map[string]interface{}{"name": "bernard"},
+			right: map[string]interface{}{"name": "bernard", "address/city": "melbourne"},
+			response: []diff.Change{
+				{Key: "address/city", Kind: diff.Added, New: "melbourne"},
+			},
+		},
+		"removed": {
+			left:  map[string]interface{}{"name": "bernard", "address/city": "melbourne"},
+			right: map[string]interface{}{"name": "bernard"},
+			response: []diff.Change{
+				{Key: "address/city", Kind: diff.Removed, Old: "melbourne"},
+			},
+		},
+		"changed": {
+			left:  map[string]interface{}{"name": "bernard", "code": "3000", "enabled": true},
+			right: map[string]interface{}{"name": "keith", "code": float64(3000), "enabled": true},
+			response: []diff.Change{
+				{Key: "code", Kind: diff.Changed, Old: "3000", New: float64(3000)},
+				{Key: "name", Kind: diff.Changed, Old: "bernard", New: "keith"},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.response, diff.Compare(test.left, test.right))
+		})
+	}
+}
+
+func TestPrint(t *testing.T) {
+	changes := []diff.Change{
+		{Key: "db/host", Kind: diff.Changed, Old: "localhost", New: "db.internal"},
+		{Key: "db/password", Kind: diff.Changed, Old: "secret", New: "s3cr3t"},
+		{Key: "db/port", Kind: diff.Added, New: float64(5432)},
+		{Key: "debug", Kind: diff.Removed, Old: true},
+	}
+
+	var b bytes.Buffer
+	diff.Print(&b, changes, map[string]bool{"db/password": true})
+
+	expected := `~ db/host: "localhost" -> "db.internal"
+~ db/password: ****** -> ******
++ db/port: 5432
+- debug: true
+`
+	assert.Equal(t, expected, b.String())
+}
@@ -0,0 +1,90 @@
+package env
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	CaseUpper = "upper"
+	CaseLower = "lower"
+	CaseKeep  = "keep"
+)
+
+var invalid = regexp.MustCompile(`[^A-Za-z0-9_]+`)
+
+// Name maps a flattened key such as db/host to an environment variable name
+// such as DB_HOST.
+func Name(key, prefix, letterCase string) string {
+	name := prefix + invalid.ReplaceAllString(strings.Trim(key, "/"), "_")
+
+	switch letterCase {
+	case CaseUpper:
+		return strings.ToUpper(name)
+	case CaseLower:
+		return strings.ToLower(name)
+	}
+
+	return name
+}
+
+// Value formats a typed parameter value as an environment variable value.
+func Value(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	}
+
+	return fmt.Sprint(v)
+}
+
+// Merge returns environ with variables built from the flattened values added,
+// values replace existing variables of the same name unless keep is set.
+func Merge(environ []string, values map[string]interface{}, prefix, letterCase string, keep bool) ([]string, error) {
+	vars := map[string]string{}
+	sources := map[string]string{}
+
+	for k, v := range values {
+		name := Name(k, prefix, letterCase)
+		if other, ok := sources[name]; ok {
+			return nil, fmt.Errorf("keys %s and %s map to the same variable %s", other, k, name)
+		}
+
+		sources[name] = k
+		vars[name] = Value(v)
+	}
+
+	var result []string
+	for _, e := range environ {
+		name := strings.SplitN(e, "=", 2)[0]
+		if _, ok := vars[name]; ok {
+			if !keep {
+				continue
+			}
+			delete(vars, name)
+		}
+
+		result = append(result, e)
+	}
+
+	names := make([]string, 0, len(vars))
+	for name := range vars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		result = append(result, name+"="+vars[name])
+	}
+
+	return result, nil
+}
@@ -0,0 +1,49 @@
+package env_test
+
+import (
+	"testing"
+
+	"github.com/b-b3rn4rd/json2ssm/pkg/env"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestName(t *testing.T) {
+	tests := map[string]struct {
+		key        string
+		prefix     string
+		letterCase string
+		response   string
+	}{
+		"upper":  {key: "db/host", letterCase: env.CaseUpper, response: "DB_HOST"},
+		"prefix": {key: "db/host", prefix: "APP_", letterCase: env.CaseUpper, response: "APP_DB_HOST"},
+		"lower":  {key: "DB/Host", letterCase: env.CaseLower, response: "db_host"},
+		"keep":   {key: "db/Host-Name", letterCase: env.CaseKeep, response: "db_Host_Name"},
+		"array":  {key: "hosts/0", letterCase: env.CaseUpper, response: "HOSTS_0"},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.response, env.Name(test.key, test.prefix, test.letterCase))
+		})
+	}
+}
+
+func TestMerge(t *testing.T) {
+	values := map[string]interface{}{
+		"db/host": "localhost",
+		"db/port": float64(5432),
+		"debug":   true,
+		"empty":   nil,
+	}
+
+	r, err := env.Merge([]string{"PATH=/bin", "DEBUG=false"}, values, "", env.CaseUpper, false)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"PATH=/bin", "DB_HOST=localhost", "DB_PORT=5432", "DEBUG=true", "EMPTY="}, r)
+
+	r, err = env.Merge([]string{"PATH=/bin", "DEBUG=false"}, values, "", env.CaseUpper, true)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"PATH=/bin", "DEBUG=false", "DB_HOST=localhost", "DB_PORT=5432", "EMPTY="}, r)
+
+	_, err = env.Merge(nil, map[string]interface{}{"db/host": "a", "db_host": "b"}, "", env.CaseUpper, false)
+	assert.Error(t, err)
+}
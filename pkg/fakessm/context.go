@@ -0,0 +1,157 @@
+package fakessm
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/ssm"
+)
+
+// canceled returns the error the SDK reports for requests made with a done
+// context.
+func canceled(ctx aws.Context) error {
+	if err := ctx.Err(); err != nil {
+		return awserr.New(request.CanceledErrorCode, "request context canceled", err)
+	}
+
+	return nil
+}
+
+func (s *SSM) PutParameterWithContext(ctx aws.Context, input *ssm.PutParameterInput, _ ...request.Option) (*ssm.PutParameterOutput, error) {
+	if err := canceled(ctx); err != nil {
+		return nil, err
+	}
+
+	return s.PutParameter(input)
+}
+
+func (s *SSM) GetParameterWithContext(ctx aws.Context, input *ssm.GetParameterInput, _ ...request.Option) (*ssm.GetParameterOutput, error) {
+	if err := canceled(ctx); err != nil {
+		return nil, err
+	}
+
+	return s.GetParameter(input)
+}
+
+func (s *SSM) GetParametersWithContext(ctx aws.Context, input *ssm.GetParametersInput, _ ...request.Option) (*ssm.GetParametersOutput, error) {
+	if err := canceled(ctx); err != nil {
+		return nil, err
+	}
+
+	return s.GetParameters(input)
+}
+
+func (s *SSM) GetParametersByPathWithContext(ctx aws.Context, input *ssm.GetParametersByPathInput, _ ...request.Option) (*ssm.GetParametersByPathOutput, error) {
+	if err := canceled(ctx); err != nil {
+		return nil, err
+	}
+
+	return s.GetParametersByPath(input)
+}
+
+func (s *SSM) GetParametersByPathPagesWithContext(ctx aws.Context, input *ssm.GetParametersByPathInput, cb func(*ssm.GetParametersByPathOutput, bool) bool, _ ...request.Option) error {
+	in := *input
+
+	for {
+		out, err := s.GetParametersByPathWithContext(ctx, &in)
+		if err != nil {
+			return err
+		}
+
+		if !cb(out, out.NextToken == nil) || out.NextToken == nil {
+			return nil
+		}
+
+		in.NextToken = out.NextToken
+	}
+}
+
+func (s *SSM) DescribeParametersWithContext(ctx aws.Context, input *ssm.DescribeParametersInput, _ ...request.Option) (*ssm.DescribeParametersOutput, error) {
+	if err := canceled(ctx); err != nil {
+		return nil, err
+	}
+
+	return s.DescribeParameters(input)
+}
+
+func (s *SSM) DescribeParametersPagesWithContext(ctx aws.Context, input *ssm.DescribeParametersInput, cb func(*ssm.DescribeParametersOutput, bool) bool, _ ...request.Option) error {
+	in := *input
+
+	for {
+		out, err := s.DescribeParametersWithContext(ctx, &in)
+		if err != nil {
+			return err
+		}
+
+		if !cb(out, out.NextToken == nil) || out.NextToken == nil {
+			return nil
+		}
+
+		in.NextToken = out.NextToken
+	}
+}
+
+func (s *SSM) GetParameterHistoryWithContext(ctx aws.Context, input *ssm.GetParameterHistoryInput, _ ...request.Option) (*ssm.GetParameterHistoryOutput, error) {
+	if err := canceled(ctx); err != nil {
+		return nil, err
+	}
+
+	return s.GetParameterHistory(input)
+}
+
+func (s *SSM) GetParameterHistoryPagesWithContext(ctx aws.Context, input *ssm.GetParameterHistoryInput, cb func(*ssm.GetParameterHistoryOutput, bool) bool, _ ...request.Option) error {
+	in := *input
+
+	for {
+		out, err := s.GetParameterHistoryWithContext(ctx, &in)
+		if err != nil {
+			return err
+		}
+
+		if !cb(out, out.NextToken == nil) || out.NextToken == nil {
+			return nil
+		}
+
+		in.NextToken = out.NextToken
+	}
+}
+
+func (s *SSM) DeleteParameterWithContext(ctx aws.Context, input *ssm.DeleteParameterInput, _ ...request.Option) (*ssm.DeleteParameterOutput, error) {
+	if err := canceled(ctx); err != nil {
+		return nil, err
+	}
+
+	return s.DeleteParameter(input)
+}
+
+func (s *SSM) DeleteParametersWithContext(ctx aws.Context, input *ssm.DeleteParametersInput, _ ...request.Option) (*ssm.DeleteParametersOutput, error) {
+	if err := canceled(ctx); err != nil {
+		return nil, err
+	}
+
+	return s.DeleteParameters(input)
+}
+
+func (s *SSM) AddTagsToResourceWithContext(ctx aws.Context, input *ssm.AddTagsToResourceInput, _ ...request.Option) (*ssm.AddTagsToResourceOutput, error) {
+	if err := canceled(ctx); err != nil {
+		return nil, err
+	}
+
+	return s.AddTagsToResource(input)
+}
+
+func (s *SSM) RemoveTagsFromResourceWithContext(ctx aws.Context, input *ssm.RemoveTagsFromResourceInput, _ ...request.Option) (*ssm.RemoveTagsFromResourceOutput, error) {
+	if err := canceled(ctx); err != nil {
+		return nil, err
+	}
+
+	return s.RemoveTagsFromResource(input)
+}
+
+func (s *SSM) ListTagsForResourceWithContext(ctx aws.Context, input *ssm.ListTagsForResourceInput, _ ...request.Option) (*ssm.ListTagsForResourceOutput, error) {
+	if err := canceled(ctx); err != nil {
+		return nil, err
+	}
+
+	return s.ListTagsForResource(input)
+}
@@ -0,0 +1,902 @@
+package fakessm
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/aws/aws-sdk-go/service/ssm/ssmiface"
+)
+
+const (
+	ErrCodeThrottling = "ThrottlingException"
+	ErrCodeValidation = "ValidationException"
+
+	DefaultKeyID = "alias/aws/ssm"
+
+	maxNameLength     = 1011
+	maxHierarchyDepth = 15
+	maxVersions       = 100
+	maxLabels         = 10
+	maxTags           = 50
+	maxStandardValue  = 4096
+	maxAdvancedValue  = 8192
+	user              = "arn:aws:iam::123456789012:user/fakessm"
+)
+
+var (
+	validName  = regexp.MustCompile(`^[a-zA-Z0-9_.\-/]+$`)
+	validLabel = regexp.MustCompile(`^[a-zA-Z_.\-][a-zA-Z0-9_.\-]{0,99}$`)
+)
+
+type version struct {
+	number         int64
+	value          string
+	valueType      string
+	keyID          string
+	description    string
+	allowedPattern string
+	tier           string
+	policies       []*ssm.ParameterInlinePolicy
+	labels         []string
+	modified       time.Time
+}
+
+type parameter struct {
+	name     string
+	versions []*version
+	tags     map[string]string
+}
+
+func (p *parameter) latest() *version {
+	return p.versions[len(p.versions)-1]
+}
+
+// SSM is an in-memory implementation of the parts of ssmiface.SSMAPI that
+// deal with parameters and their tags. Calling any other method panics.
+type SSM struct {
+	ssmiface.SSMAPI
+
+	// ThrottleEvery makes every n-th call fail with a throttling error.
+	ThrottleEvery int
+	// Now returns the time recorded as the last modified date.
+	Now func() time.Time
+
+	mx     sync.Mutex
+	calls  int
+	params map[string]*parameter
+}
+
+func New() *SSM {
+	return &SSM{
+		Now:    time.Now,
+		params: map[string]*parameter{},
+	}
+}
+
+func errorf(code, format string, args ...interface{}) error {
+	return awserr.New(code, fmt.Sprintf(format, args...), nil)
+}
+
+func (s *SSM) call() error {
+	s.calls++
+	if s.ThrottleEvery > 0 && s.calls%s.ThrottleEvery == 0 {
+		return errorf(ErrCodeThrottling, "Rate exceeded")
+	}
+
+	return nil
+}
+
+func validateName(name string) error {
+	if name == "" || len(name) > maxNameLength {
+		return errorf(ErrCodeValidation, "parameter name must be between 1 and %d characters", maxNameLength)
+	}
+
+	if !validName.MatchString(name) {
+		return errorf(ErrCodeValidation, "parameter name %s can only contain a-z, A-Z, 0-9, _, ., - and /", name)
+	}
+
+	if strings.Contains(name, "/") && (!strings.HasPrefix(name, "/") || strings.HasSuffix(name, "/") || strings.Contains(name, "//")) {
+		return errorf(ErrCodeValidation, "parameter name %s is not a fully qualified hierarchy", name)
+	}
+
+	if strings.Count(name, "/") > maxHierarchyDepth {
+		return errorf(ssm.ErrCodeHierarchyLevelLimitExceededException, "parameter name %s exceeds %d hierarchy levels", name, maxHierarchyDepth)
+	}
+
+	root := strings.ToLower(strings.TrimPrefix(name, "/"))
+	if strings.HasPrefix(root, "aws") || strings.HasPrefix(root, "ssm") {
+		return errorf(ErrCodeValidation, "parameter name %s can't be prefixed with aws or ssm", name)
+	}
+
+	return nil
+}
+
+func encrypt(keyID, value string) string {
+	return base64.StdEncoding.EncodeToString([]byte(keyID + "|" + value))
+}
+
+func (s *SSM) output(p *parameter, v *version, decrypt bool, selector string) *ssm.Parameter {
+	value := v.value
+	if v.valueType == ssm.ParameterTypeSecureString && !decrypt {
+		value = encrypt(v.keyID, v.value)
+	}
+
+	out := &ssm.Parameter{
+		ARN:              aws.String("arn:aws:ssm:us-east-1:123456789012:parameter/" + strings.TrimPrefix(p.name, "/")),
+		LastModifiedDate: aws.Time(v.modified),
+		Name:             aws.String(p.name),
+		Type:             aws.String(v.valueType),
+		Value:            aws.String(value),
+		Version:          aws.Int64(v.number),
+	}
+
+	if selector != "" {
+		out.Selector = aws.String(selector)
+	}
+
+	return out
+}
+
+func metadata(p *parameter) *ssm.ParameterMetadata {
+	v := p.latest()
+	m := &ssm.ParameterMetadata{
+		LastModifiedDate: aws.Time(v.modified),
+		LastModifiedUser: aws.String(user),
+		Name:             aws.String(p.name),
+		Policies:         v.policies,
+		Tier:             aws.String(v.tier),
+		Type:             aws.String(v.valueType),
+		Version:          aws.Int64(v.number),
+	}
+
+	if v.keyID != "" {
+		m.KeyId = aws.String(v.keyID)
+	}
+
+	if v.description != "" {
+		m.Description = aws.String(v.description)
+	}
+
+	if v.allowedPattern != "" {
+		m.AllowedPattern = aws.String(v.allowedPattern)
+	}
+
+	return m
+}
+
+func (s *SSM) lookup(selector string) (*parameter, *version, error) {
+	name := selector
+	var label string
+	if i := strings.LastIndex(selector, ":"); i >= 0 {
+		name, label = selector[:i], selector[i+1:]
+	}
+
+	p, ok := s.params[name]
+	if !ok {
+		return nil, nil, errorf(ssm.ErrCodeParameterNotFound, "parameter %s not found", name)
+	}
+
+	if label == "" {
+		return p, p.latest(), nil
+	}
+
+	if n, err := strconv.ParseInt(label, 10, 64); err == nil {
+		for _, v := range p.versions {
+			if v.number == n {
+				return p, v, nil
+			}
+		}
+
+		return nil, nil, errorf(ssm.ErrCodeParameterVersionNotFound, "version %d of %s not found", n, name)
+	}
+
+	for _, v := range p.versions {
+		for _, l := range v.labels {
+			if l == label {
+				return p, v, nil
+			}
+		}
+	}
+
+	return nil, nil, errorf(ssm.ErrCodeParameterVersionNotFound, "label %s of %s not found", label, name)
+}
+
+func (s *SSM) PutParameter(input *ssm.PutParameterInput) (*ssm.PutParameterOutput, error) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	if err := s.call(); err != nil {
+		return nil, err
+	}
+
+	name := aws.StringValue(input.Name)
+	if err := validateName(name); err != nil {
+		return nil, err
+	}
+
+	v := &version{
+		value:          aws.StringValue(input.Value),
+		valueType:      aws.StringValue(input.Type),
+		description:    aws.StringValue(input.Description),
+		allowedPattern: aws.StringValue(input.AllowedPattern),
+		tier:           aws.StringValue(input.Tier),
+		modified:       s.Now(),
+	}
+
+	p, exists := s.params[name]
+	if exists && !aws.BoolValue(input.Overwrite) {
+		return nil, errorf(ssm.ErrCodeParameterAlreadyExists, "parameter %s already exists", name)
+	}
+
+	if v.valueType == "" && exists {
+		v.valueType = p.latest().valueType
+	}
+
+	switch v.valueType {
+	case ssm.ParameterTypeString, ssm.ParameterTypeStringList:
+		if input.KeyId != nil {
+			return nil, errorf(ErrCodeValidation, "KeyId is only supported by %s", ssm.ParameterTypeSecureString)
+		}
+	case ssm.ParameterTypeSecureString:
+		v.keyID = aws.StringValue(input.KeyId)
+		if v.keyID == "" {
+			v.keyID = DefaultKeyID
+		}
+	default:
+		return nil, errorf(ssm.ErrCodeUnsupportedParameterType, "parameter type %s is not supported", v.valueType)
+	}
+
+	if v.tier == "" {
+		v.tier = ssm.ParameterTierStandard
+		if exists {
+			v.tier = p.latest().tier
+		}
+	}
+
+	if exists && p.latest().tier == ssm.ParameterTierAdvanced && v.tier == ssm.ParameterTierStandard {
+		return nil, errorf(ErrCodeValidation, "parameter %s can't be downgraded to %s tier", name, v.tier)
+	}
+
+	limit := maxStandardValue
+	switch v.tier {
+	case ssm.ParameterTierStandard:
+	case ssm.ParameterTierAdvanced:
+		limit = maxAdvancedValue
+	default:
+		return nil, errorf(ErrCodeValidation, "parameter tier %s is not supported", v.tier)
+	}
+
+	if v.value == "" || len(v.value) > limit {
+		return nil, errorf(ErrCodeValidation, "parameter value must be between 1 and %d bytes in %s tier", limit, v.tier)
+	}
+
+	if v.allowedPattern != "" {
+		re, err := regexp.Compile(v.allowedPattern)
+		if err != nil {
+			return nil, errorf(ssm.ErrCodeInvalidAllowedPatternException, "%s", err)
+		}
+
+		if !re.MatchString(v.value) {
+			return nil, errorf(ssm.ErrCodeParameterPatternMismatchException, "value doesn't match pattern %s", v.allowedPattern)
+		}
+	}
+
+	if input.Policies != nil {
+		if v.tier != ssm.ParameterTierAdvanced {
+			return nil, errorf(ErrCodeValidation, "policies are only supported in %s tier", ssm.ParameterTierAdvanced)
+		}
+
+		var policies []map[string]interface{}
+		if err := json.Unmarshal([]byte(aws.StringValue(input.Policies)), &policies); err != nil {
+			return nil, errorf(ssm.ErrCodeInvalidPolicyAttributeException, "%s", err)
+		}
+
+		for _, policy := range policies {
+			text, _ := json.Marshal(policy)
+			v.policies = append(v.policies, &ssm.ParameterInlinePolicy{
+				PolicyStatus: aws.String("Pending"),
+				PolicyText:   aws.String(string(text)),
+				PolicyType:   aws.String(fmt.Sprint(policy["Type"])),
+			})
+		}
+	}
+
+	if !exists {
+		p = &parameter{name: name, tags: map[string]string{}}
+		s.params[name] = p
+	}
+
+	if len(p.versions) == maxVersions {
+		if len(p.versions[0].labels) > 0 {
+			return nil, errorf(ssm.ErrCodeParameterMaxVersionLimitExceeded, "oldest version of %s has labels", name)
+		}
+		p.versions = p.versions[1:]
+	}
+
+	v.number = 1
+	if exists {
+		v.number = p.latest().number + 1
+	}
+	p.versions = append(p.versions, v)
+
+	return &ssm.PutParameterOutput{Version: aws.Int64(v.number)}, nil
+}
+
+func (s *SSM) GetParameter(input *ssm.GetParameterInput) (*ssm.GetParameterOutput, error) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	if err := s.call(); err != nil {
+		return nil, err
+	}
+
+	selector := aws.StringValue(input.Name)
+	p, v, err := s.lookup(selector)
+	if err != nil {
+		return nil, err
+	}
+
+	sel := strings.TrimPrefix(selector, p.name)
+
+	return &ssm.GetParameterOutput{Parameter: s.output(p, v, aws.BoolValue(input.WithDecryption), sel)}, nil
+}
+
+func (s *SSM) GetParameters(input *ssm.GetParametersInput) (*ssm.GetParametersOutput, error) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	if err := s.call(); err != nil {
+		return nil, err
+	}
+
+	if len(input.Names) == 0 || len(input.Names) > 10 {
+		return nil, errorf(ErrCodeValidation, "between 1 and 10 names must be specified")
+	}
+
+	out := &ssm.GetParametersOutput{}
+	for _, name := range input.Names {
+		p, v, err := s.lookup(aws.StringValue(name))
+		if err != nil {
+			out.InvalidParameters = append(out.InvalidParameters, name)
+			continue
+		}
+
+		sel := strings.TrimPrefix(aws.StringValue(name), p.name)
+		out.Parameters = append(out.Parameters, s.output(p, v, aws.BoolValue(input.WithDecryption), sel))
+	}
+
+	return out, nil
+}
+
+func (s *SSM) sortedNames() []string {
+	names := make([]string, 0, len(s.params))
+	for name := range s.params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+func page(total int, token *string, maxResults *int64, limit int64) (int, int, *string, error) {
+	start := 0
+	if token != nil {
+		n, err := strconv.Atoi(aws.StringValue(token))
+		if err != nil || n < 0 || n > total {
+			return 0, 0, nil, errorf(ssm.ErrCodeInvalidNextToken, "invalid next token")
+		}
+		start = n
+	}
+
+	size := limit
+	if maxResults != nil {
+		size = aws.Int64Value(maxResults)
+		if size < 1 || size > limit {
+			return 0, 0, nil, errorf(ErrCodeValidation, "MaxResults must be between 1 and %d", limit)
+		}
+	}
+
+	end := start + int(size)
+	if end >= total {
+		return start, total, nil, nil
+	}
+
+	return start, end, aws.String(strconv.Itoa(end)), nil
+}
+
+func underPath(name, path string, recursive bool) bool {
+	prefix := strings.TrimSuffix(path, "/") + "/"
+	if !strings.HasPrefix(name, prefix) {
+		return false
+	}
+
+	return recursive || !strings.Contains(strings.TrimPrefix(name, prefix), "/")
+}
+
+func values(f *ssm.ParameterStringFilter) []string {
+	return aws.StringValueSlice(f.Values)
+}
+
+func contains(list []string, value string) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// match reports whether the parameter matches common filters, path and label
+// filters are handled by callers.
+func match(p *parameter, f *ssm.ParameterStringFilter) (bool, error) {
+	key := aws.StringValue(f.Key)
+	option := aws.StringValue(f.Option)
+	v := p.latest()
+
+	switch {
+	case key == "Type":
+		return contains(values(f), v.valueType), nil
+	case key == "KeyId":
+		return contains(values(f), v.keyID), nil
+	case key == "Tier":
+		return contains(values(f), v.tier), nil
+	case key == "Name":
+		for _, value := range values(f) {
+			if option == "BeginsWith" && strings.HasPrefix(p.name, value) || option != "BeginsWith" && p.name == value {
+				return true, nil
+			}
+		}
+		return false, nil
+	case strings.HasPrefix(key, "tag:"):
+		tag, ok := p.tags[strings.TrimPrefix(key, "tag:")]
+		return ok && (len(f.Values) == 0 || contains(values(f), tag)), nil
+	}
+
+	return false, errorf(ssm.ErrCodeInvalidFilterKey, "filter key %s is not supported", key)
+}
+
+func (s *SSM) GetParametersByPath(input *ssm.GetParametersByPathInput) (*ssm.GetParametersByPathOutput, error) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	if err := s.call(); err != nil {
+		return nil, err
+	}
+
+	path := aws.StringValue(input.Path)
+	if !strings.HasPrefix(path, "/") {
+		return nil, errorf(ErrCodeValidation, "path %s must begin with /", path)
+	}
+
+	type result struct {
+		p *parameter
+		v *version
+	}
+	var results []result
+
+	for _, name := range s.sortedNames() {
+		if path != "/" && !underPath(name, path, aws.BoolValue(input.Recursive)) {
+			continue
+		}
+
+		if path == "/" && !aws.BoolValue(input.Recursive) && strings.Count(name, "/") > 1 {
+			continue
+		}
+
+		p := s.params[name]
+		v := p.latest()
+		ok := true
+
+		for _, f := range input.ParameterFilters {
+			if aws.StringValue(f.Key) == "Label" {
+				labeled := false
+				for _, candidate := range p.versions {
+					for _, label := range candidate.labels {
+						if contains(values(f), label) {
+							v, labeled = candidate, true
+						}
+					}
+				}
+				ok = ok && labeled
+				continue
+			}
+
+			matched, err := match(p, f)
+			if err != nil {
+				return nil, err
+			}
+			ok = ok && matched
+		}
+
+		if ok {
+			results = append(results, result{p, v})
+		}
+	}
+
+	start, end, next, err := page(len(results), input.NextToken, input.MaxResults, 10)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &ssm.GetParametersByPathOutput{NextToken: next}
+	for _, r := range results[start:end] {
+		out.Parameters = append(out.Parameters, s.output(r.p, r.v, aws.BoolValue(input.WithDecryption), ""))
+	}
+
+	return out, nil
+}
+
+func (s *SSM) GetParametersByPathPages(input *ssm.GetParametersByPathInput, cb func(*ssm.GetParametersByPathOutput, bool) bool) error {
+	in := *input
+
+	for {
+		out, err := s.GetParametersByPath(&in)
+		if err != nil {
+			return err
+		}
+
+		if !cb(out, out.NextToken == nil) || out.NextToken == nil {
+			return nil
+		}
+
+		in.NextToken = out.NextToken
+	}
+}
+
+func (s *SSM) DescribeParameters(input *ssm.DescribeParametersInput) (*ssm.DescribeParametersOutput, error) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	if err := s.call(); err != nil {
+		return nil, err
+	}
+
+	var results []*parameter
+
+	for _, name := range s.sortedNames() {
+		p := s.params[name]
+		ok := true
+
+		for _, f := range input.Filters {
+			matched, err := match(p, &ssm.ParameterStringFilter{Key: f.Key, Values: f.Values, Option: aws.String("BeginsWith")})
+			if err != nil {
+				return nil, err
+			}
+			ok = ok && matched
+		}
+
+		for _, f := range input.ParameterFilters {
+			if aws.StringValue(f.Key) == "Path" {
+				recursive := aws.StringValue(f.Option) == "Recursive"
+				inPath := false
+				for _, path := range values(f) {
+					inPath = inPath || path == "/" && (recursive || strings.Count(name, "/") == 1) || underPath(name, path, recursive)
+				}
+				ok = ok && inPath
+				continue
+			}
+
+			matched, err := match(p, f)
+			if err != nil {
+				return nil, err
+			}
+			ok = ok && matched
+		}
+
+		if ok {
+			results = append(results, p)
+		}
+	}
+
+	start, end, next, err := page(len(results), input.NextToken, input.MaxResults, 50)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &ssm.DescribeParametersOutput{NextToken: next}
+	for _, p := range results[start:end] {
+		out.Parameters = append(out.Parameters, metadata(p))
+	}
+
+	return out, nil
+}
+
+func (s *SSM) DescribeParametersPages(input *ssm.DescribeParametersInput, cb func(*ssm.DescribeParametersOutput, bool) bool) error {
+	in := *input
+
+	for {
+		out, err := s.DescribeParameters(&in)
+		if err != nil {
+			return err
+		}
+
+		if !cb(out, out.NextToken == nil) || out.NextToken == nil {
+			return nil
+		}
+
+		in.NextToken = out.NextToken
+	}
+}
+
+func (s *SSM) GetParameterHistory(input *ssm.GetParameterHistoryInput) (*ssm.GetParameterHistoryOutput, error) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	if err := s.call(); err != nil {
+		return nil, err
+	}
+
+	p, ok := s.params[aws.StringValue(input.Name)]
+	if !ok {
+		return nil, errorf(ssm.ErrCodeParameterNotFound, "parameter %s not found", aws.StringValue(input.Name))
+	}
+
+	start, end, next, err := page(len(p.versions), input.NextToken, input.MaxResults, 50)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &ssm.GetParameterHistoryOutput{NextToken: next}
+	for _, v := range p.versions[start:end] {
+		value := v.value
+		if v.valueType == ssm.ParameterTypeSecureString && !aws.BoolValue(input.WithDecryption) {
+			value = encrypt(v.keyID, v.value)
+		}
+
+		h := &ssm.ParameterHistory{
+			LastModifiedDate: aws.Time(v.modified),
+			LastModifiedUser: aws.String(user),
+			Name:             aws.String(p.name),
+			Policies:         v.policies,
+			Tier:             aws.String(v.tier),
+			Type:             aws.String(v.valueType),
+			Value:            aws.String(value),
+			Version:          aws.Int64(v.number),
+		}
+
+		if len(v.labels) > 0 {
+			h.Labels = aws.StringSlice(v.labels)
+		}
+
+		if v.keyID != "" {
+			h.KeyId = aws.String(v.keyID)
+		}
+
+		if v.description != "" {
+			h.Description = aws.String(v.description)
+		}
+
+		if v.allowedPattern != "" {
+			h.AllowedPattern = aws.String(v.allowedPattern)
+		}
+
+		out.Parameters = append(out.Parameters, h)
+	}
+
+	return out, nil
+}
+
+func (s *SSM) GetParameterHistoryPages(input *ssm.GetParameterHistoryInput, cb func(*ssm.GetParameterHistoryOutput, bool) bool) error {
+	in := *input
+
+	for {
+		out, err := s.GetParameterHistory(&in)
+		if err != nil {
+			return err
+		}
+
+		if !cb(out, out.NextToken == nil) || out.NextToken == nil {
+			return nil
+		}
+
+		in.NextToken = out.NextToken
+	}
+}
+
+func (s *SSM) LabelParameterVersion(input *ssm.LabelParameterVersionInput) (*ssm.LabelParameterVersionOutput, error) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	if err := s.call(); err != nil {
+		return nil, err
+	}
+
+	name := aws.StringValue(input.Name)
+	p, ok := s.params[name]
+	if !ok {
+		return nil, errorf(ssm.ErrCodeParameterNotFound, "parameter %s not found", name)
+	}
+
+	target := p.latest()
+	if input.ParameterVersion != nil {
+		target = nil
+		for _, v := range p.versions {
+			if v.number == aws.Int64Value(input.ParameterVersion) {
+				target = v
+			}
+		}
+
+		if target == nil {
+			return nil, errorf(ssm.ErrCodeParameterVersionNotFound, "version %d of %s not found", aws.Int64Value(input.ParameterVersion), name)
+		}
+	}
+
+	out := &ssm.LabelParameterVersionOutput{}
+	var labels []string
+
+	for _, label := range aws.StringValueSlice(input.Labels) {
+		lower := strings.ToLower(label)
+		if !validLabel.MatchString(label) || strings.HasPrefix(lower, "aws") || strings.HasPrefix(lower, "ssm") {
+			out.InvalidLabels = append(out.InvalidLabels, aws.String(label))
+			continue
+		}
+
+		if !contains(target.labels, label) {
+			labels = append(labels, label)
+		}
+	}
+
+	if len(target.labels)+len(labels) > maxLabels {
+		return nil, errorf(ssm.ErrCodeParameterVersionLabelLimitExceeded, "version %d of %s can have at most %d labels", target.number, name, maxLabels)
+	}
+
+	for _, v := range p.versions {
+		var kept []string
+		for _, l := range v.labels {
+			if !contains(labels, l) {
+				kept = append(kept, l)
+			}
+		}
+		v.labels = kept
+	}
+
+	target.labels = append(target.labels, labels...)
+
+	return out, nil
+}
+
+func (s *SSM) DeleteParameter(input *ssm.DeleteParameterInput) (*ssm.DeleteParameterOutput, error) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	if err := s.call(); err != nil {
+		return nil, err
+	}
+
+	name := aws.StringValue(input.Name)
+	if _, ok := s.params[name]; !ok {
+		return nil, errorf(ssm.ErrCodeParameterNotFound, "parameter %s not found", name)
+	}
+
+	delete(s.params, name)
+
+	return &ssm.DeleteParameterOutput{}, nil
+}
+
+func (s *SSM) DeleteParameters(input *ssm.DeleteParametersInput) (*ssm.DeleteParametersOutput, error) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	if err := s.call(); err != nil {
+		return nil, err
+	}
+
+	if len(input.Names) == 0 || len(input.Names) > 10 {
+		return nil, errorf(ErrCodeValidation, "between 1 and 10 names must be specified")
+	}
+
+	out := &ssm.DeleteParametersOutput{}
+	for _, name := range input.Names {
+		if _, ok := s.params[aws.StringValue(name)]; !ok {
+			out.InvalidParameters = append(out.InvalidParameters, name)
+			continue
+		}
+
+		delete(s.params, aws.StringValue(name))
+		out.DeletedParameters = append(out.DeletedParameters, name)
+	}
+
+	return out, nil
+}
+
+func (s *SSM) resource(resourceType, id *string) (*parameter, error) {
+	if aws.StringValue(resourceType) != ssm.ResourceTypeForTaggingParameter {
+		return nil, errorf(ssm.ErrCodeInvalidResourceType, "resource type %s is not supported", aws.StringValue(resourceType))
+	}
+
+	p, ok := s.params[aws.StringValue(id)]
+	if !ok {
+		return nil, errorf(ssm.ErrCodeInvalidResourceId, "parameter %s not found", aws.StringValue(id))
+	}
+
+	return p, nil
+}
+
+func (s *SSM) AddTagsToResource(input *ssm.AddTagsToResourceInput) (*ssm.AddTagsToResourceOutput, error) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	if err := s.call(); err != nil {
+		return nil, err
+	}
+
+	p, err := s.resource(input.ResourceType, input.ResourceId)
+	if err != nil {
+		return nil, err
+	}
+
+	tags := map[string]string{}
+	for k, v := range p.tags {
+		tags[k] = v
+	}
+
+	for _, tag := range input.Tags {
+		tags[aws.StringValue(tag.Key)] = aws.StringValue(tag.Value)
+	}
+
+	if len(tags) > maxTags {
+		return nil, errorf(ssm.ErrCodeTooManyTagsError, "parameter %s can have at most %d tags", p.name, maxTags)
+	}
+
+	p.tags = tags
+
+	return &ssm.AddTagsToResourceOutput{}, nil
+}
+
+func (s *SSM) RemoveTagsFromResource(input *ssm.RemoveTagsFromResourceInput) (*ssm.RemoveTagsFromResourceOutput, error) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	if err := s.call(); err != nil {
+		return nil, err
+	}
+
+	p, err := s.resource(input.ResourceType, input.ResourceId)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, key := range input.TagKeys {
+		delete(p.tags, aws.StringValue(key))
+	}
+
+	return &ssm.RemoveTagsFromResourceOutput{}, nil
+}
+
+func (s *SSM) ListTagsForResource(input *ssm.ListTagsForResourceInput) (*ssm.ListTagsForResourceOutput, error) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	if err := s.call(); err != nil {
+		return nil, err
+	}
+
+	p, err := s.resource(input.ResourceType, input.ResourceId)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(p.tags))
+	for k := range p.tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	out := &ssm.ListTagsForResourceOutput{TagList: []*ssm.Tag{}}
+	for _, k := range keys {
+		out.TagList = append(out.TagList, &ssm.Tag{Key: aws.String(k), Value: aws.String(p.tags[k])})
+	}
+
+	return out, nil
+}
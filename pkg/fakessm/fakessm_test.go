@@ -0,0 +1,208 @@
+package fakessm_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/b-b3rn4rd/json2ssm/pkg/fakessm"
+	"github.com/stretchr/testify/assert"
+)
+
+func code(err error) string {
+	if aerr, ok := err.(awserr.Error); ok {
+		return aerr.Code()
+	}
+
+	return ""
+}
+
+func TestPutParameterValidation(t *testing.T) {
+	tests := map[string]struct {
+		input *ssm.PutParameterInput
+		code  string
+	}{
+		"valid": {
+			input: &ssm.PutParameterInput{Name: aws.String("/app/name"), Value: aws.String("v"), Type: aws.String(ssm.ParameterTypeString)},
+		},
+		"invalid characters": {
+			input: &ssm.PutParameterInput{Name: aws.String("/app/na me"), Value: aws.String("v"), Type: aws.String(ssm.ParameterTypeString)},
+			code:  fakessm.ErrCodeValidation,
+		},
+		"reserved prefix": {
+			input: &ssm.PutParameterInput{Name: aws.String("/aws/name"), Value: aws.String("v"), Type: aws.String(ssm.ParameterTypeString)},
+			code:  fakessm.ErrCodeValidation,
+		},
+		"too deep": {
+			input: &ssm.PutParameterInput{Name: aws.String(strings.Repeat("/a", 16)), Value: aws.String("v"), Type: aws.String(ssm.ParameterTypeString)},
+			code:  ssm.ErrCodeHierarchyLevelLimitExceededException,
+		},
+		"empty value": {
+			input: &ssm.PutParameterInput{Name: aws.String("/app/name"), Value: aws.String(""), Type: aws.String(ssm.ParameterTypeString)},
+			code:  fakessm.ErrCodeValidation,
+		},
+		"standard value too large": {
+			input: &ssm.PutParameterInput{Name: aws.String("/app/name"), Value: aws.String(strings.Repeat("v", 4097)), Type: aws.String(ssm.ParameterTypeString)},
+			code:  fakessm.ErrCodeValidation,
+		},
+		"advanced value": {
+			input: &ssm.PutParameterInput{Name: aws.String("/app/name"), Value: aws.String(strings.Repeat("v", 4097)), Type: aws.String(ssm.ParameterTypeString), Tier: aws.String(ssm.ParameterTierAdvanced)},
+		},
+		"key id with string": {
+			input: &ssm.PutParameterInput{Name: aws.String("/app/name"), Value: aws.String("v"), Type: aws.String(ssm.ParameterTypeString), KeyId: aws.String("alias/app")},
+			code:  fakessm.ErrCodeValidation,
+		},
+		"unsupported type": {
+			input: &ssm.PutParameterInput{Name: aws.String("/app/name"), Value: aws.String("v"), Type: aws.String("Number")},
+			code:  ssm.ErrCodeUnsupportedParameterType,
+		},
+		"pattern mismatch": {
+			input: &ssm.PutParameterInput{Name: aws.String("/app/port"), Value: aws.String("http"), Type: aws.String(ssm.ParameterTypeString), AllowedPattern: aws.String(`^\d+$`)},
+			code:  ssm.ErrCodeParameterPatternMismatchException,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := fakessm.New().PutParameter(test.input)
+			assert.Equal(t, test.code, code(err))
+		})
+	}
+}
+
+func TestVersionsAndLabels(t *testing.T) {
+	s := fakessm.New()
+
+	for _, value := range []string{"one", "two", "three"} {
+		_, err := s.PutParameter(&ssm.PutParameterInput{
+			Name:      aws.String("/app/secret"),
+			Value:     aws.String(value),
+			Type:      aws.String(ssm.ParameterTypeSecureString),
+			Overwrite: aws.Bool(true),
+		})
+		assert.NoError(t, err)
+	}
+
+	_, err := s.PutParameter(&ssm.PutParameterInput{
+		Name:  aws.String("/app/secret"),
+		Value: aws.String("four"),
+		Type:  aws.String(ssm.ParameterTypeSecureString),
+	})
+	assert.Equal(t, ssm.ErrCodeParameterAlreadyExists, code(err))
+
+	_, err = s.LabelParameterVersion(&ssm.LabelParameterVersionInput{
+		Name:             aws.String("/app/secret"),
+		ParameterVersion: aws.Int64(2),
+		Labels:           aws.StringSlice([]string{"prod"}),
+	})
+	assert.NoError(t, err)
+
+	out, err := s.GetParameter(&ssm.GetParameterInput{Name: aws.String("/app/secret:prod"), WithDecryption: aws.Bool(true)})
+	assert.NoError(t, err)
+	assert.Equal(t, "two", aws.StringValue(out.Parameter.Value))
+	assert.Equal(t, ":prod", aws.StringValue(out.Parameter.Selector))
+
+	out, err = s.GetParameter(&ssm.GetParameterInput{Name: aws.String("/app/secret")})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), aws.Int64Value(out.Parameter.Version))
+	assert.NotEqual(t, "three", aws.StringValue(out.Parameter.Value))
+
+	label, err := s.LabelParameterVersion(&ssm.LabelParameterVersionInput{
+		Name:   aws.String("/app/secret"),
+		Labels: aws.StringSlice([]string{"prod", "aws-reserved", "1st"}),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"aws-reserved", "1st"}, aws.StringValueSlice(label.InvalidLabels))
+
+	history, err := s.GetParameterHistory(&ssm.GetParameterHistoryInput{Name: aws.String("/app/secret")})
+	assert.NoError(t, err)
+	assert.Len(t, history.Parameters, 3)
+	assert.Nil(t, history.Parameters[1].Labels)
+	assert.Equal(t, []string{"prod"}, aws.StringValueSlice(history.Parameters[2].Labels))
+}
+
+func TestGetParametersByPathPagination(t *testing.T) {
+	s := fakessm.New()
+
+	for _, name := range []string{"/app/a", "/app/b", "/app/c/d", "/other/e"} {
+		_, err := s.PutParameter(&ssm.PutParameterInput{Name: aws.String(name), Value: aws.String("v"), Type: aws.String(ssm.ParameterTypeString)})
+		assert.NoError(t, err)
+	}
+
+	var names []string
+	pages := 0
+	err := s.GetParametersByPathPages(&ssm.GetParametersByPathInput{
+		Path:       aws.String("/app"),
+		Recursive:  aws.Bool(true),
+		MaxResults: aws.Int64(2),
+	}, func(page *ssm.GetParametersByPathOutput, lastPage bool) bool {
+		pages++
+		for _, p := range page.Parameters {
+			names = append(names, aws.StringValue(p.Name))
+		}
+		return true
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, pages)
+	assert.Equal(t, []string{"/app/a", "/app/b", "/app/c/d"}, names)
+
+	out, err := s.GetParametersByPath(&ssm.GetParametersByPathInput{Path: aws.String("/app")})
+	assert.NoError(t, err)
+	assert.Len(t, out.Parameters, 2)
+}
+
+func TestGetParametersByPathFilters(t *testing.T) {
+	s := fakessm.New()
+
+	for name, paramType := range map[string]string{
+		"/app/secret": ssm.ParameterTypeSecureString,
+		"/app/name":   ssm.ParameterTypeString,
+		"/app/other":  ssm.ParameterTypeSecureString,
+	} {
+		_, err := s.PutParameter(&ssm.PutParameterInput{Name: aws.String(name), Value: aws.String("v"), Type: aws.String(paramType)})
+		assert.NoError(t, err)
+	}
+
+	for _, name := range []string{"/app/secret", "/app/name"} {
+		_, err := s.LabelParameterVersion(&ssm.LabelParameterVersionInput{Name: aws.String(name), Labels: aws.StringSlice([]string{"prod"})})
+		assert.NoError(t, err)
+	}
+
+	typeFilter := &ssm.ParameterStringFilter{Key: aws.String("Type"), Values: aws.StringSlice([]string{ssm.ParameterTypeSecureString})}
+	labelFilter := &ssm.ParameterStringFilter{Key: aws.String("Label"), Values: aws.StringSlice([]string{"prod"})}
+
+	tests := map[string][]*ssm.ParameterStringFilter{
+		"type then label": {typeFilter, labelFilter},
+		"label then type": {labelFilter, typeFilter},
+	}
+
+	for name, filters := range tests {
+		t.Run(name, func(t *testing.T) {
+			out, err := s.GetParametersByPath(&ssm.GetParametersByPathInput{
+				Path:             aws.String("/app"),
+				ParameterFilters: filters,
+			})
+			assert.NoError(t, err)
+
+			var names []string
+			for _, p := range out.Parameters {
+				names = append(names, aws.StringValue(p.Name))
+			}
+			assert.Equal(t, []string{"/app/secret"}, names)
+		})
+	}
+}
+
+func TestThrottling(t *testing.T) {
+	s := fakessm.New()
+	s.ThrottleEvery = 2
+
+	_, err := s.DescribeParameters(&ssm.DescribeParametersInput{})
+	assert.NoError(t, err)
+
+	_, err = s.DescribeParameters(&ssm.DescribeParametersInput{})
+	assert.Equal(t, fakessm.ErrCodeThrottling, code(err))
+}
@@ -0,0 +1,79 @@
+package render
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"text/template"
+)
+
+// Funcs returns helper functions available to templates.
+func Funcs() template.FuncMap {
+	return template.FuncMap{
+		"default":  defaultValue,
+		"required": required,
+		"toJSON":   toJSON,
+		"b64enc":   b64enc,
+	}
+}
+
+// Render executes the template text against the given tree.
+func Render(name, text string, tree interface{}) ([]byte, error) {
+	t, err := template.New(name).Funcs(Funcs()).Parse(text)
+	if err != nil {
+		return nil, err
+	}
+
+	var b bytes.Buffer
+	if err = t.Execute(&b, tree); err != nil {
+		return nil, err
+	}
+
+	return b.Bytes(), nil
+}
+
+func empty(v interface{}) bool {
+	if v == nil {
+		return true
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map:
+		return rv.Len() == 0
+	}
+
+	return false
+}
+
+func defaultValue(d interface{}, v ...interface{}) interface{} {
+	if len(v) == 0 || empty(v[0]) {
+		return d
+	}
+
+	return v[0]
+}
+
+func required(msg string, v interface{}) (interface{}, error) {
+	if empty(v) {
+		return nil, errors.New(msg)
+	}
+
+	return v, nil
+}
+
+func toJSON(v interface{}) (string, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+
+	return string(raw), nil
+}
+
+func b64enc(v interface{}) string {
+	return base64.StdEncoding.EncodeToString([]byte(fmt.Sprint(v)))
+}
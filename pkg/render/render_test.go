@@ -0,0 +1,59 @@
+package render_test
+
+import (
+	"testing"
+
+	"github.com/b-b3rn4rd/json2ssm/pkg/render"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRender(t *testing.T) {
+	tree := map[string]interface{}{
+		"db": map[string]interface{}{
+			"host":  "localhost",
+			"port":  float64(5432),
+			"empty": "",
+		},
+		"hosts": []interface{}{"a", "b"},
+	}
+
+	tests := map[string]struct {
+		text     string
+		response string
+		err      bool
+	}{
+		"value": {
+			text:     "host={{ .db.host }}:{{ .db.port }}",
+			response: "host=localhost:5432",
+		},
+		"default": {
+			text:     `{{ .db.user | default "admin" }} {{ .db.empty | default "none" }} {{ .db.host | default "x" }}`,
+			response: "admin none localhost",
+		},
+		"required": {
+			text: `{{ required "db.user is required" .db.user }}`,
+			err:  true,
+		},
+		"toJSON": {
+			text:     "{{ toJSON .hosts }}",
+			response: `["a","b"]`,
+		},
+		"b64enc": {
+			text:     "{{ b64enc .db.host }}",
+			response: "bG9jYWxob3N0",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			r, err := render.Render(name, test.text, tree)
+			if test.err {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, test.response, string(r))
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package schema
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/xeipuuv/gojsonschema"
+)
+
+// Violation is a single mismatch between a document and the schema, Key is
+// the flattened parameter name relative to the document root and Pointer is
+// the JSON pointer of the same value.
+type Violation struct {
+	Key     string
+	Pointer string
+	Message string
+}
+
+func (v Violation) String() string {
+	return fmt.Sprintf("%s (%s): %s", v.Key, v.Pointer, v.Message)
+}
+
+type Schema struct {
+	schema *gojsonschema.Schema
+}
+
+// Load reads the JSON schema from the file, relative $ref are resolved against
+// the file location.
+func Load(path string) (*Schema, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+
+	s, err := gojsonschema.NewSchema(gojsonschema.NewReferenceLoader("file://" + filepath.ToSlash(abs)))
+	if err != nil {
+		return nil, fmt.Errorf("can't load schema %s: %s", path, err)
+	}
+
+	return &Schema{schema: s}, nil
+}
+
+func New(raw []byte) (*Schema, error) {
+	s, err := gojsonschema.NewSchema(gojsonschema.NewBytesLoader(raw))
+	if err != nil {
+		return nil, err
+	}
+
+	return &Schema{schema: s}, nil
+}
+
+// Validate returns violations sorted by key, an error is returned only when
+// the document can't be validated at all.
+func (s *Schema) Validate(doc interface{}) ([]Violation, error) {
+	result, err := s.schema.Validate(gojsonschema.NewGoLoader(doc))
+	if err != nil {
+		return nil, err
+	}
+
+	var violations []Violation
+	for _, e := range result.Errors() {
+		// the context is a "."-separated path from (root), a separator that
+		// can't appear in keys is used to split it back into segments
+		segments := strings.Split(e.Context().String("\x00"), "\x00")[1:]
+
+		if e.Type() == "required" {
+			if property, ok := e.Details()["property"].(string); ok {
+				segments = append(segments, property)
+			}
+		}
+
+		violations = append(violations, Violation{
+			Key:     strings.Join(segments, "/"),
+			Pointer: pointer(segments),
+			Message: e.Description(),
+		})
+	}
+
+	sort.SliceStable(violations, func(i, j int) bool {
+		return violations[i].Key < violations[j].Key
+	})
+
+	return violations, nil
+}
+
+func pointer(segments []string) string {
+	if len(segments) == 0 {
+		return ""
+	}
+
+	escaped := make([]string, len(segments))
+	for i, s := range segments {
+		escaped[i] = strings.Replace(strings.Replace(s, "~", "~0", -1), "/", "~1", -1)
+	}
+
+	return "/" + strings.Join(escaped, "/")
+}
@@ -0,0 +1,87 @@
+package schema_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/b-b3rn4rd/json2ssm/pkg/schema"
+	"github.com/stretchr/testify/assert"
+)
+
+const raw = `{
+  "type": "object",
+  "required": ["name", "db"],
+  "properties": {
+    "name": {"type": "string"},
+    "db": {
+      "type": "object",
+      "required": ["host"],
+      "properties": {
+        "host": {"type": "string"},
+        "port": {"type": "integer"}
+      }
+    },
+    "hosts": {"type": "array", "items": {"type": "string"}}
+  }
+}`
+
+func TestValidate(t *testing.T) {
+	s, err := schema.New([]byte(raw))
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		doc      interface{}
+		expected []schema.Violation
+	}{
+		{
+			name: "valid",
+			doc: map[string]interface{}{
+				"name": "app",
+				"db":   map[string]interface{}{"host": "localhost", "port": float64(5432)},
+			},
+		},
+		{
+			name: "violations",
+			doc: map[string]interface{}{
+				"db":    map[string]interface{}{"port": "5432"},
+				"hosts": []interface{}{"a", float64(1)},
+			},
+			expected: []schema.Violation{
+				{Key: "db/host", Pointer: "/db/host", Message: "host is required"},
+				{Key: "db/port", Pointer: "/db/port", Message: "Invalid type. Expected: integer, given: string"},
+				{Key: "hosts/1", Pointer: "/hosts/1", Message: "Invalid type. Expected: string, given: integer"},
+				{Key: "name", Pointer: "/name", Message: "name is required"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			violations, err := s.Validate(tt.doc)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, violations)
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir, err := ioutil.TempDir("", "schema")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "schema.json")
+	assert.NoError(t, ioutil.WriteFile(path, []byte(raw), 0644))
+
+	s, err := schema.Load(path)
+	assert.NoError(t, err)
+
+	violations, err := s.Validate(map[string]interface{}{"name": "app", "db": map[string]interface{}{"host": "db"}})
+	assert.NoError(t, err)
+	assert.Empty(t, violations)
+
+	_, err = schema.Load(filepath.Join(dir, "missing.json"))
+	assert.Error(t, err)
+}
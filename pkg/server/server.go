@@ -0,0 +1,253 @@
+package server
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/b-b3rn4rd/json2ssm/pkg/source"
+	"github.com/b-b3rn4rd/json2ssm/pkg/storage"
+	"github.com/sirupsen/logrus"
+)
+
+const treePrefix = "/v1/tree"
+
+// Options configures caching, authentication and write access of the server.
+type Options struct {
+	// CacheTTL is how long exported trees are served from memory, zero
+	// disables caching.
+	CacheTTL time.Duration
+	// Token enables bearer token authentication when not empty.
+	Token string
+	// ReadOnly rejects PUT and DELETE requests.
+	ReadOnly bool
+	// MaxSize is the maximum size of a PUT request body, zero means no limit.
+	MaxSize int64
+	// Insecure allows decrypted reads and writes without a Token.
+	Insecure bool
+}
+
+type entry struct {
+	body    []byte
+	expires time.Time
+}
+
+// Server exposes parameter subtrees of a storage as JSON documents.
+type Server struct {
+	store   storage.Storage
+	logger  *logrus.Logger
+	options Options
+	now     func() time.Time
+
+	mx    sync.Mutex
+	cache map[string]entry
+}
+
+func New(store storage.Storage, logger *logrus.Logger, options Options) *Server {
+	return &Server{
+		store:   store,
+		logger:  logger,
+		options: options,
+		now:     time.Now,
+		cache:   map[string]entry{},
+	}
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+func (s *Server) respond(w http.ResponseWriter, status int, body []byte) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(body)
+}
+
+func (s *Server) fail(w http.ResponseWriter, status int, msg string) {
+	raw, _ := json.Marshal(errorResponse{Error: msg})
+	s.respond(w, status, raw)
+}
+
+func (s *Server) authorized(r *http.Request) bool {
+	if s.options.Token == "" {
+		return true
+	}
+
+	expected := []byte("Bearer " + s.options.Token)
+
+	return subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), expected) == 1
+}
+
+// trusted reports whether requests may decrypt or change parameters, without
+// a token only an explicitly insecure server allows that.
+func (s *Server) trusted() bool {
+	return s.options.Token != "" || s.options.Insecure
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != treePrefix && !strings.HasPrefix(r.URL.Path, treePrefix+"/") {
+		s.fail(w, http.StatusNotFound, "not found")
+		return
+	}
+
+	if !s.authorized(r) {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		s.fail(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	path := strings.TrimPrefix(r.URL.Path, treePrefix)
+	if path == "" {
+		path = "/"
+	}
+
+	logger := s.logger.WithField("path", path).WithField("method", r.Method)
+	logger.Debug("handling request")
+
+	switch r.Method {
+	case http.MethodGet:
+		s.get(w, r, path)
+	case http.MethodPut, http.MethodDelete:
+		if s.options.ReadOnly {
+			s.fail(w, http.StatusMethodNotAllowed, "server is read-only")
+			return
+		}
+
+		if !s.trusted() {
+			s.fail(w, http.StatusForbidden, "writes require a token")
+			return
+		}
+
+		if r.Method == http.MethodPut {
+			s.put(w, r, path)
+		} else {
+			s.delete(w, path)
+		}
+	default:
+		s.fail(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+func (s *Server) get(w http.ResponseWriter, r *http.Request, path string) {
+	decrypt, _ := strconv.ParseBool(r.URL.Query().Get("decrypt"))
+	if decrypt && !s.trusted() {
+		s.fail(w, http.StatusForbidden, "decryption requires a token")
+		return
+	}
+
+	key := path + "?decrypt=" + strconv.FormatBool(decrypt)
+
+	s.mx.Lock()
+	cached, ok := s.cache[key]
+	s.mx.Unlock()
+
+	if ok && s.now().Before(cached.expires) {
+		s.respond(w, http.StatusOK, cached.body)
+		return
+	}
+
+	tree, err := s.store.Export(path, decrypt)
+	if err != nil {
+		s.logger.WithField("path", path).WithError(err).Info("can't export")
+		s.fail(w, http.StatusBadGateway, err.Error())
+		return
+	}
+
+	if tree == nil {
+		s.fail(w, http.StatusNotFound, "no parameters found under "+path)
+		return
+	}
+
+	body, err := json.Marshal(tree)
+	if err != nil {
+		s.fail(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if s.options.CacheTTL > 0 {
+		s.mx.Lock()
+		s.cache[key] = entry{body: body, expires: s.now().Add(s.options.CacheTTL)}
+		s.mx.Unlock()
+	}
+
+	s.respond(w, http.StatusOK, body)
+}
+
+// keys prefixes flattened keys with the path in the form expected by Import
+// and Delete.
+func keys(path string, values map[string]interface{}) map[string]interface{} {
+	prefix := strings.Trim(path, "/")
+	result := make(map[string]interface{}, len(values))
+
+	for k, v := range values {
+		if prefix != "" {
+			k = prefix + "/" + k
+		}
+		result[k] = v
+	}
+
+	return result
+}
+
+func (s *Server) invalidate() {
+	s.mx.Lock()
+	s.cache = map[string]entry{}
+	s.mx.Unlock()
+}
+
+func (s *Server) put(w http.ResponseWriter, r *http.Request, path string) {
+	body := r.Body
+	if s.options.MaxSize > 0 {
+		body = http.MaxBytesReader(w, r.Body, s.options.MaxSize)
+	}
+
+	raw, err := ioutil.ReadAll(body)
+	if err != nil {
+		s.fail(w, http.StatusRequestEntityTooLarge, err.Error())
+		return
+	}
+
+	j := source.JSON{}
+	values, err := j.Flatten(bytes.NewReader(raw))
+	if err != nil {
+		s.fail(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	encrypt, _ := strconv.ParseBool(r.URL.Query().Get("encrypt"))
+
+	total, err := s.store.Import(keys(path, values), r.URL.Query().Get("message"), encrypt)
+	s.invalidate()
+	if err != nil {
+		s.logger.WithField("path", path).WithError(err).Info("can't import")
+		s.fail(w, http.StatusBadGateway, err.Error())
+		return
+	}
+
+	raw, _ = json.Marshal(map[string]int{"imported": total})
+	s.respond(w, http.StatusOK, raw)
+}
+
+func (s *Server) delete(w http.ResponseWriter, path string) {
+	total, err := s.store.DeletePath(path)
+	s.invalidate()
+	if err != nil {
+		s.logger.WithField("path", path).WithError(err).Info("can't delete")
+		s.fail(w, http.StatusBadGateway, err.Error())
+		return
+	}
+
+	if total == 0 {
+		s.fail(w, http.StatusNotFound, "no parameters found under "+path)
+		return
+	}
+
+	raw, _ := json.Marshal(map[string]int{"deleted": total})
+	s.respond(w, http.StatusOK, raw)
+}
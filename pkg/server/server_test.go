@@ -0,0 +1,199 @@
+package server_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/b-b3rn4rd/json2ssm/pkg/fakessm"
+	"github.com/b-b3rn4rd/json2ssm/pkg/server"
+	"github.com/b-b3rn4rd/json2ssm/pkg/storage"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func newStore(t *testing.T) (*storage.FileStorage, func()) {
+	dir, err := ioutil.TempDir("", "server")
+	assert.NoError(t, err)
+
+	return storage.NewFile(filepath.Join(dir, "parameters.json")), func() { os.RemoveAll(dir) }
+}
+
+func do(h http.Handler, method, target, body, token string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, target, strings.NewReader(body))
+	if token != "" {
+		r.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	return w
+}
+
+func TestServer(t *testing.T) {
+	store, cleanup := newStore(t)
+	defer cleanup()
+
+	logger, _ := test.NewNullLogger()
+	srv := server.New(store, logger, server.Options{Insecure: true})
+
+	w := do(srv, http.MethodGet, "/v1/tree/app", "", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = do(srv, http.MethodPut, "/v1/tree/app", `{"db": {"host": "localhost", "port": 5432}}`, "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"imported": 2}`, w.Body.String())
+
+	w = do(srv, http.MethodGet, "/v1/tree/app", "", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"db": {"host": "localhost", "port": 5432}}`, w.Body.String())
+
+	w = do(srv, http.MethodGet, "/v1/tree/app/db", "", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"host": "localhost", "port": 5432}`, w.Body.String())
+
+	w = do(srv, http.MethodPut, "/v1/tree/app", `not json`, "")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = do(srv, http.MethodDelete, "/v1/tree/app/db", "", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"deleted": 2}`, w.Body.String())
+
+	w = do(srv, http.MethodGet, "/v1/tree/app", "", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = do(srv, http.MethodGet, "/v2/tree/app", "", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = do(srv, http.MethodPost, "/v1/tree/app", "", "")
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+}
+
+func TestServerDeleteSSM(t *testing.T) {
+	logger, _ := test.NewNullLogger()
+	srv := server.New(storage.New(fakessm.New(), logger), logger, server.Options{Insecure: true})
+
+	w := do(srv, http.MethodPut, "/v1/tree/app", `{"name": "json2ssm", "hosts": ["a", "b"]}`, "")
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = do(srv, http.MethodDelete, "/v1/tree/app", "", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"deleted": 3}`, w.Body.String())
+
+	w = do(srv, http.MethodDelete, "/v1/tree/app", "", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestServerAuthAndReadOnly(t *testing.T) {
+	store, cleanup := newStore(t)
+	defer cleanup()
+
+	_, err := store.Import(map[string]interface{}{"app/name": "json2ssm"}, "", false)
+	assert.NoError(t, err)
+
+	logger, _ := test.NewNullLogger()
+	srv := server.New(store, logger, server.Options{Token: "secret", ReadOnly: true})
+
+	tests := []struct {
+		name   string
+		method string
+		token  string
+		status int
+	}{
+		{"missing token", http.MethodGet, "", http.StatusUnauthorized},
+		{"wrong token", http.MethodGet, "wrong", http.StatusUnauthorized},
+		{"read", http.MethodGet, "secret", http.StatusOK},
+		{"write", http.MethodPut, "secret", http.StatusMethodNotAllowed},
+		{"delete", http.MethodDelete, "secret", http.StatusMethodNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := do(srv, tt.method, "/v1/tree/app", `{"name": "other"}`, tt.token)
+			assert.Equal(t, tt.status, w.Code)
+		})
+	}
+}
+
+func TestServerWithoutToken(t *testing.T) {
+	store, cleanup := newStore(t)
+	defer cleanup()
+
+	_, err := store.Import(map[string]interface{}{"app/name": "json2ssm"}, "", false)
+	assert.NoError(t, err)
+
+	logger, _ := test.NewNullLogger()
+
+	tests := []struct {
+		name     string
+		method   string
+		target   string
+		insecure bool
+		status   int
+	}{
+		{"read", http.MethodGet, "/v1/tree/app", false, http.StatusOK},
+		{"decrypted read", http.MethodGet, "/v1/tree/app?decrypt=true", false, http.StatusForbidden},
+		{"write", http.MethodPut, "/v1/tree/app", false, http.StatusForbidden},
+		{"delete", http.MethodDelete, "/v1/tree/app", false, http.StatusForbidden},
+		{"insecure decrypted read", http.MethodGet, "/v1/tree/app?decrypt=true", true, http.StatusOK},
+		{"insecure write", http.MethodPut, "/v1/tree/app", true, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := server.New(store, logger, server.Options{Insecure: tt.insecure})
+
+			w := do(srv, tt.method, tt.target, `{"name": "json2ssm"}`, "")
+			assert.Equal(t, tt.status, w.Code)
+		})
+	}
+}
+
+func TestServerMaxSize(t *testing.T) {
+	store, cleanup := newStore(t)
+	defer cleanup()
+
+	logger, _ := test.NewNullLogger()
+	srv := server.New(store, logger, server.Options{MaxSize: 20, Insecure: true})
+
+	w := do(srv, http.MethodPut, "/v1/tree/app", `{"name": "json2ssm"}`, "")
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = do(srv, http.MethodPut, "/v1/tree/app", `{"name": "json2ssm", "port": 8080}`, "")
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	assert.JSONEq(t, `{"error": "http: request body too large"}`, w.Body.String())
+}
+
+func TestServerCache(t *testing.T) {
+	store, cleanup := newStore(t)
+	defer cleanup()
+
+	_, err := store.Import(map[string]interface{}{"app/name": "json2ssm"}, "", false)
+	assert.NoError(t, err)
+
+	logger, _ := test.NewNullLogger()
+	srv := server.New(store, logger, server.Options{CacheTTL: time.Hour, Insecure: true})
+
+	w := do(srv, http.MethodGet, "/v1/tree/app", "", "")
+	assert.JSONEq(t, `{"name": "json2ssm"}`, w.Body.String())
+
+	// changes made behind the server are not visible until the entry expires
+	_, err = store.Import(map[string]interface{}{"app/name": "changed"}, "", false)
+	assert.NoError(t, err)
+
+	w = do(srv, http.MethodGet, "/v1/tree/app", "", "")
+	assert.JSONEq(t, `{"name": "json2ssm"}`, w.Body.String())
+
+	// changes made through the server invalidate the cache
+	w = do(srv, http.MethodPut, "/v1/tree/app", `{"name": "updated"}`, "")
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = do(srv, http.MethodGet, "/v1/tree/app", "", "")
+	assert.JSONEq(t, `{"name": "updated"}`, w.Body.String())
+}
@@ -0,0 +1,113 @@
+package source
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// Filter narrows flattened values down to a part of the document before they
+// are imported or deleted.
+type Filter struct {
+	// Select is a JSON pointer to the subtree that is kept, e.g.
+	// /services/payments, the whole document is kept when it is empty.
+	Select string
+	// Exclude drops keys relative to the selected subtree matching any of the
+	// globs, keys are dropped as well when one of their parents matches, e.g.
+	// secrets or */password.
+	Exclude []string
+	// Path re-roots the selected subtree, its keys are moved under the path
+	// instead of being kept where they are in the document.
+	Path string
+}
+
+// Apply returns the values left after selecting and excluding keys.
+func (f *Filter) Apply(values map[string]interface{}) (map[string]interface{}, error) {
+	prefix, err := pointerKey(f.Select)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := CheckPatterns(f.Exclude); err != nil {
+		return nil, err
+	}
+
+	root := strings.Trim(f.Path, "/")
+	out := map[string]interface{}{}
+	found := false
+
+	for k, v := range values {
+		rel := k
+		if prefix != "" {
+			if k != prefix && !strings.HasPrefix(k, prefix+"/") {
+				continue
+			}
+
+			rel = strings.TrimPrefix(strings.TrimPrefix(k, prefix), "/")
+		}
+
+		found = true
+
+		if MatchKey(rel, f.Exclude) {
+			continue
+		}
+
+		switch {
+		case root == "":
+			out[k] = v
+		case rel == "":
+			out[root] = v
+		default:
+			out[root+"/"+rel] = v
+		}
+	}
+
+	if !found && prefix != "" {
+		return nil, fmt.Errorf("nothing found at %s", f.Select)
+	}
+
+	return out, nil
+}
+
+// pointerKey converts a JSON pointer into the flattened key it points to.
+func pointerKey(pointer string) (string, error) {
+	if pointer == "" {
+		return "", nil
+	}
+
+	if !strings.HasPrefix(pointer, "/") {
+		return "", fmt.Errorf("invalid JSON pointer %s, it must start with /", pointer)
+	}
+
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.Replace(strings.Replace(token, "~1", "/", -1), "~0", "~", -1)
+	}
+
+	return strings.Join(tokens, "/"), nil
+}
+
+// CheckPatterns returns an error for the first malformed glob.
+func CheckPatterns(patterns []string) error {
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid pattern %s: %s", pattern, err)
+		}
+	}
+
+	return nil
+}
+
+// MatchKey reports whether a flattened key or one of its parents matches any
+// of the globs, see path.Match.
+func MatchKey(key string, patterns []string) bool {
+	for _, pattern := range patterns {
+		for k := key; k != "" && k != "."; k = path.Dir(k) {
+			if ok, _ := path.Match(pattern, k); ok {
+				return true
+			}
+		}
+	}
+
+	return false
+}
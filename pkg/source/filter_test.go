@@ -0,0 +1,86 @@
+package source_test
+
+import (
+	"testing"
+
+	"github.com/b-b3rn4rd/json2ssm/pkg/source"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFilter(t *testing.T) {
+	values := map[string]interface{}{
+		"name":                          "platform",
+		"services/payments/host":        "pay.internal",
+		"services/payments/db/password": "secret",
+		"services/payments/db/user":     "pay",
+		"services/payments/replicas/0":  "a",
+		"services/orders/host":          "orders.internal",
+		"services/orders/db/password":   "other",
+		"services/payments-legacy/host": "legacy.internal",
+	}
+
+	tests := map[string]struct {
+		filter   source.Filter
+		expected map[string]interface{}
+		err      string
+	}{
+		"empty": {
+			filter:   source.Filter{},
+			expected: values,
+		},
+		"select": {
+			filter: source.Filter{Select: "/services/payments"},
+			expected: map[string]interface{}{
+				"services/payments/host":        "pay.internal",
+				"services/payments/db/password": "secret",
+				"services/payments/db/user":     "pay",
+				"services/payments/replicas/0":  "a",
+			},
+		},
+		"select and re-root": {
+			filter: source.Filter{Select: "/services/payments", Exclude: []string{"*/password", "replicas"}, Path: "/payments/"},
+			expected: map[string]interface{}{
+				"payments/host":    "pay.internal",
+				"payments/db/user": "pay",
+			},
+		},
+		"select a leaf": {
+			filter:   source.Filter{Select: "/services/orders/host", Path: "/orders/url"},
+			expected: map[string]interface{}{"orders/url": "orders.internal"},
+		},
+		"exclude and path": {
+			filter: source.Filter{Exclude: []string{"services/*/db", "name"}, Path: "/prod"},
+			expected: map[string]interface{}{
+				"prod/services/payments/host":        "pay.internal",
+				"prod/services/payments/replicas/0":  "a",
+				"prod/services/orders/host":          "orders.internal",
+				"prod/services/payments-legacy/host": "legacy.internal",
+			},
+		},
+		"missing": {
+			filter: source.Filter{Select: "/services/billing"},
+			err:    "nothing found at /services/billing",
+		},
+		"invalid pointer": {
+			filter: source.Filter{Select: "services"},
+			err:    "invalid JSON pointer services, it must start with /",
+		},
+		"invalid pattern": {
+			filter: source.Filter{Exclude: []string{"[a"}},
+			err:    "invalid pattern [a: syntax error in pattern",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			out, err := tt.filter.Apply(values)
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, out)
+		})
+	}
+}
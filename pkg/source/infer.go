@@ -0,0 +1,45 @@
+package source
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// number matches JSON-like numbers, values with leading zeros such as zip
+// codes stay strings.
+var number = regexp.MustCompile(`^-?(0|[1-9][0-9]*)(\.[0-9]+)?$`)
+
+// infer converts true, false and numbers to bool and float64, as JSON
+// documents are flattened, everything else is kept as a string.
+func infer(v string) interface{} {
+	switch v {
+	case "true":
+		return true
+	case "false":
+		return false
+	}
+
+	if number.MatchString(v) {
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			return f
+		}
+	}
+
+	return v
+}
+
+// entry adds a key and value to flattened values, the separator in the key is
+// replaced with / to build the parameter hierarchy.
+func entry(values map[string]interface{}, key, value, separator string, inferTypes bool) {
+	if separator != "" {
+		key = strings.Replace(key, separator, "/", -1)
+	}
+
+	if inferTypes {
+		values[key] = infer(value)
+		return
+	}
+
+	values[key] = value
+}
@@ -0,0 +1,158 @@
+package source
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const refPrefix = "ref:"
+
+var envName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Interpolator expands placeholders in string values of a document:
+// ${NAME} and ${NAME:-default} are replaced with environment variables,
+// ${ref:/name} with the value of another parameter and $$ with a literal $.
+type Interpolator struct {
+	// Env looks up environment variables, os.LookupEnv is used when nil.
+	Env func(name string) (string, bool)
+	// Ref returns the value of a parameter, references are rejected when nil.
+	Ref func(name string) (string, error)
+}
+
+// InterpolationError lists every placeholder that couldn't be resolved.
+type InterpolationError struct {
+	Errors []string
+}
+
+func (e *InterpolationError) Error() string {
+	return fmt.Sprintf("can't interpolate: %s", strings.Join(e.Errors, "; "))
+}
+
+// Interpolate returns a copy of the document with placeholders expanded in
+// string values, keys are left as they are.
+func (in *Interpolator) Interpolate(doc interface{}) (interface{}, error) {
+	var errs []string
+	result := in.walk(doc, "", &errs)
+
+	if len(errs) > 0 {
+		sort.Strings(errs)
+		return nil, &InterpolationError{Errors: errs}
+	}
+
+	return result, nil
+}
+
+func (in *Interpolator) walk(doc interface{}, key string, errs *[]string) interface{} {
+	join := func(k string) string {
+		if key == "" {
+			return k
+		}
+		return key + "/" + k
+	}
+
+	switch doc := doc.(type) {
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(doc))
+		for k, v := range doc {
+			m[k] = in.walk(v, join(k), errs)
+		}
+		return m
+	case []interface{}:
+		s := make([]interface{}, len(doc))
+		for i, v := range doc {
+			s[i] = in.walk(v, join(strconv.Itoa(i)), errs)
+		}
+		return s
+	case string:
+		v, err := in.expand(doc)
+		if err != nil {
+			*errs = append(*errs, fmt.Sprintf("/%s: %s", key, err))
+		}
+		return v
+	}
+
+	return doc
+}
+
+func (in *Interpolator) expand(s string) (string, error) {
+	if !strings.Contains(s, "$") {
+		return s, nil
+	}
+
+	var b strings.Builder
+
+	for i := 0; i < len(s); i++ {
+		if s[i] != '$' || i+1 == len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+
+		switch s[i+1] {
+		case '$':
+			b.WriteByte('$')
+			i++
+		case '{':
+			end := strings.IndexByte(s[i+2:], '}')
+			if end < 0 {
+				return s, fmt.Errorf("unterminated placeholder %s", s[i:])
+			}
+
+			v, err := in.resolve(s[i+2 : i+2+end])
+			if err != nil {
+				return s, err
+			}
+
+			b.WriteString(v)
+			i += end + 2
+		default:
+			b.WriteByte('$')
+		}
+	}
+
+	return b.String(), nil
+}
+
+func (in *Interpolator) resolve(expr string) (string, error) {
+	if strings.HasPrefix(expr, refPrefix) {
+		name := strings.TrimPrefix(expr, refPrefix)
+		if in.Ref == nil {
+			return "", fmt.Errorf("references are not supported, can't resolve %s", name)
+		}
+
+		v, err := in.Ref(name)
+		if err != nil {
+			return "", fmt.Errorf("can't resolve reference %s: %s", name, err)
+		}
+
+		return v, nil
+	}
+
+	name, def := expr, ""
+	hasDefault := false
+	if i := strings.Index(expr, ":-"); i >= 0 {
+		name, def, hasDefault = expr[:i], expr[i+2:], true
+	}
+
+	if !envName.MatchString(name) {
+		return "", fmt.Errorf("invalid placeholder ${%s}", expr)
+	}
+
+	lookup := in.Env
+	if lookup == nil {
+		lookup = os.LookupEnv
+	}
+
+	v, ok := lookup(name)
+	if hasDefault && v == "" {
+		return def, nil
+	}
+	if !ok {
+		return "", fmt.Errorf("environment variable %s is not set", name)
+	}
+
+	return v, nil
+}
@@ -0,0 +1,83 @@
+package source_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/b-b3rn4rd/json2ssm/pkg/source"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInterpolate(t *testing.T) {
+	env := map[string]string{"STAGE": "prod", "EMPTY": ""}
+	refs := map[string]string{"/shared/db/host": "db.internal"}
+
+	in := &source.Interpolator{
+		Env: func(name string) (string, bool) {
+			v, ok := env[name]
+			return v, ok
+		},
+		Ref: func(name string) (string, error) {
+			v, ok := refs[name]
+			if !ok {
+				return "", fmt.Errorf("parameter not found")
+			}
+			return v, nil
+		},
+	}
+
+	tests := map[string]struct {
+		doc      interface{}
+		expected interface{}
+		err      string
+	}{
+		"env": {
+			doc:      map[string]interface{}{"url": "https://${STAGE}.example.com", "port": float64(80)},
+			expected: map[string]interface{}{"url": "https://prod.example.com", "port": float64(80)},
+		},
+		"default": {
+			doc:      []interface{}{"${MISSING:-dev}", "${EMPTY:-dev}", "${STAGE:-dev}"},
+			expected: []interface{}{"dev", "dev", "prod"},
+		},
+		"ref": {
+			doc:      map[string]interface{}{"db": map[string]interface{}{"host": "${ref:/shared/db/host}:5432"}},
+			expected: map[string]interface{}{"db": map[string]interface{}{"host": "db.internal:5432"}},
+		},
+		"literal": {
+			doc:      map[string]interface{}{"price": "$$5 or $5 for ${STAGE}", "tpl": "$${STAGE}"},
+			expected: map[string]interface{}{"price": "$5 or $5 for prod", "tpl": "${STAGE}"},
+		},
+		"errors": {
+			doc: map[string]interface{}{
+				"a": "${MISSING}",
+				"b": []interface{}{"${ref:/missing}"},
+				"c": "${STAGE",
+				"d": "${not valid}",
+			},
+			err: "can't interpolate: /a: environment variable MISSING is not set; " +
+				"/b/0: can't resolve reference /missing: parameter not found; " +
+				"/c: unterminated placeholder ${STAGE; " +
+				"/d: invalid placeholder ${not valid}",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			doc, err := in.Interpolate(tt.doc)
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, doc)
+		})
+	}
+}
+
+func TestInterpolateWithoutRefs(t *testing.T) {
+	in := &source.Interpolator{}
+
+	_, err := in.Interpolate("${ref:/shared/db/host}")
+	assert.EqualError(t, err, "can't interpolate: /: references are not supported, can't resolve /shared/db/host")
+}
@@ -0,0 +1,206 @@
+package source
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+const (
+	// CaseKeep uses keys exactly as written.
+	CaseKeep = ""
+	// CaseKebab turns camelCase keys into kebab-case, e.g. dbHost into db-host.
+	CaseKebab = "kebab"
+	// CaseLower lowercases keys, it can't be reversed.
+	CaseLower = "lower"
+
+	// IndexPlain names array items by their index, e.g. hosts/0.
+	IndexPlain = "plain"
+	// IndexBrackets appends the index in brackets to the array, e.g. hosts[0].
+	IndexBrackets = "brackets"
+)
+
+// brackets matches an index appended to a key, e.g. [0] in hosts[0].
+var brackets = regexp.MustCompile(`\[([0-9]+)\]$`)
+
+// KeyFormat names parameters after flattening, Format turns / separated keys
+// into parameter names and Parse applies the exact inverse, so documents
+// round-trip whatever the naming convention.
+type KeyFormat struct {
+	// Separator joins keys of nested objects, / by default.
+	Separator string
+	// Case is one of CaseKeep, CaseKebab and CaseLower.
+	Case string
+	// Prefix and Suffix are added to every name, Parse removes them where
+	// present as exported names are relative to the path.
+	Prefix string
+	Suffix string
+	// Index is one of IndexPlain, the default, and IndexBrackets.
+	Index string
+}
+
+func (f *KeyFormat) separator() string {
+	if f.Separator == "" {
+		return "/"
+	}
+
+	return f.Separator
+}
+
+// Format renames flattened values, keys that would collide, contain the
+// separator or don't survive the kebab-case conversion are reported as they
+// couldn't be parsed back.
+func (f *KeyFormat) Format(values map[string]interface{}) (map[string]interface{}, error) {
+	out := make(map[string]interface{}, len(values))
+	from := make(map[string]string, len(values))
+
+	for _, key := range sortedKeys(values) {
+		name, err := f.name(key)
+		if err != nil {
+			return nil, err
+		}
+
+		if other, ok := from[name]; ok {
+			return nil, fmt.Errorf("keys %s and %s are both named %s", other, key, name)
+		}
+
+		from[name] = key
+		out[name] = values[key]
+	}
+
+	return out, nil
+}
+
+// Parse turns names created by Format back into / separated keys.
+func (f *KeyFormat) Parse(values map[string]interface{}) (map[string]interface{}, error) {
+	out := make(map[string]interface{}, len(values))
+	from := make(map[string]string, len(values))
+
+	for _, name := range sortedKeys(values) {
+		key := f.key(name)
+
+		if other, ok := from[key]; ok {
+			return nil, fmt.Errorf("names %s and %s are both parsed into %s", other, name, key)
+		}
+
+		from[key] = name
+		out[key] = values[name]
+	}
+
+	return out, nil
+}
+
+func (f *KeyFormat) name(key string) (string, error) {
+	var b strings.Builder
+
+	for i, part := range strings.Split(key, "/") {
+		if _, err := strconv.Atoi(part); err == nil && f.Index == IndexBrackets {
+			b.WriteString("[" + part + "]")
+			continue
+		}
+
+		if strings.Contains(part, f.separator()) {
+			return "", fmt.Errorf("key %s contains the separator %s", key, f.separator())
+		}
+
+		transformed := f.transform(part)
+		if f.Case == CaseKebab && camel(transformed) != part {
+			return "", fmt.Errorf("key %s can't be converted to kebab-case and back", key)
+		}
+
+		if i > 0 {
+			b.WriteString(f.separator())
+		}
+		b.WriteString(transformed)
+	}
+
+	return f.Prefix + b.String() + f.Suffix, nil
+}
+
+func (f *KeyFormat) key(name string) string {
+	name = strings.TrimSuffix(strings.TrimPrefix(name, f.Prefix), f.Suffix)
+
+	var parts []string
+	for _, part := range strings.Split(name, f.separator()) {
+		var indexes []string
+		if f.Index == IndexBrackets {
+			for m := brackets.FindStringSubmatch(part); m != nil; m = brackets.FindStringSubmatch(part) {
+				indexes = append([]string{m[1]}, indexes...)
+				part = strings.TrimSuffix(part, m[0])
+			}
+		}
+
+		if part != "" || len(indexes) == 0 {
+			parts = append(parts, f.untransform(part))
+		}
+		parts = append(parts, indexes...)
+	}
+
+	return strings.Join(parts, "/")
+}
+
+func (f *KeyFormat) transform(part string) string {
+	switch f.Case {
+	case CaseLower:
+		return strings.ToLower(part)
+	case CaseKebab:
+		return kebab(part)
+	}
+
+	return part
+}
+
+func (f *KeyFormat) untransform(part string) string {
+	if f.Case == CaseKebab {
+		return camel(part)
+	}
+
+	return part
+}
+
+// kebab splits words on lower to upper case changes, e.g. dbHost into
+// db-host. Keys camel can't restore, such as HTTPPort or db-host, are rejected
+// by Format.
+func kebab(s string) string {
+	r := []rune(s)
+	var b strings.Builder
+
+	for i, c := range r {
+		if i > 0 && unicode.IsUpper(c) {
+			prev := r[i-1]
+			nextLower := i+1 < len(r) && unicode.IsLower(r[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				b.WriteRune('-')
+			}
+		}
+		b.WriteRune(unicode.ToLower(c))
+	}
+
+	return b.String()
+}
+
+func camel(s string) string {
+	words := strings.Split(s, "-")
+	for i := 1; i < len(words); i++ {
+		r := []rune(words[i])
+		if len(r) > 0 {
+			r[0] = unicode.ToUpper(r[0])
+		}
+		words[i] = string(r)
+	}
+
+	return strings.Join(words, "")
+}
+
+func sortedKeys(values map[string]interface{}) []string {
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
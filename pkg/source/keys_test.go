@@ -0,0 +1,108 @@
+package source_test
+
+import (
+	"testing"
+
+	"github.com/b-b3rn4rd/json2ssm/pkg/source"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeyFormat(t *testing.T) {
+	values := map[string]interface{}{
+		"app/dbHost":         "localhost",
+		"app/hosts/0":        "a",
+		"app/hosts/1":        "b",
+		"app/matrix/0/1":     float64(1),
+		"app/servers/0/name": "web",
+	}
+
+	tests := map[string]struct {
+		format   source.KeyFormat
+		expected map[string]interface{}
+	}{
+		"default": {
+			format:   source.KeyFormat{},
+			expected: values,
+		},
+		"separator and kebab-case": {
+			format: source.KeyFormat{Separator: ".", Case: source.CaseKebab},
+			expected: map[string]interface{}{
+				"app.db-host":        "localhost",
+				"app.hosts.0":        "a",
+				"app.hosts.1":        "b",
+				"app.matrix.0.1":     float64(1),
+				"app.servers.0.name": "web",
+			},
+		},
+		"brackets with prefix and suffix": {
+			format: source.KeyFormat{Prefix: "/team/", Suffix: "-v1", Index: source.IndexBrackets},
+			expected: map[string]interface{}{
+				"/team/app/dbHost-v1":          "localhost",
+				"/team/app/hosts[0]-v1":        "a",
+				"/team/app/hosts[1]-v1":        "b",
+				"/team/app/matrix[0][1]-v1":    float64(1),
+				"/team/app/servers[0]/name-v1": "web",
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			named, err := tt.format.Format(values)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, named)
+
+			parsed, err := tt.format.Parse(named)
+			assert.NoError(t, err)
+			assert.Equal(t, values, parsed)
+		})
+	}
+}
+
+func TestKeyFormatLower(t *testing.T) {
+	format := source.KeyFormat{Case: source.CaseLower}
+
+	named, err := format.Format(map[string]interface{}{"App/DB": "x"})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"app/db": "x"}, named)
+
+	parsed, err := format.Parse(named)
+	assert.NoError(t, err)
+	assert.Equal(t, named, parsed)
+}
+
+func TestKeyFormatErrors(t *testing.T) {
+	tests := map[string]struct {
+		format source.KeyFormat
+		values map[string]interface{}
+		err    string
+	}{
+		"separator in key": {
+			format: source.KeyFormat{Separator: "."},
+			values: map[string]interface{}{"db.host": "x"},
+			err:    "key db.host contains the separator .",
+		},
+		"collision": {
+			format: source.KeyFormat{Case: source.CaseLower},
+			values: map[string]interface{}{"Host": "x", "host": "y"},
+			err:    "keys Host and host are both named host",
+		},
+		"kebab": {
+			format: source.KeyFormat{Case: source.CaseKebab},
+			values: map[string]interface{}{"db-host": "x"},
+			err:    "key db-host can't be converted to kebab-case and back",
+		},
+		"kebab acronym": {
+			format: source.KeyFormat{Case: source.CaseKebab},
+			values: map[string]interface{}{"app/HTTPPort": "x"},
+			err:    "key app/HTTPPort can't be converted to kebab-case and back",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := tt.format.Format(tt.values)
+			assert.EqualError(t, err, tt.err)
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package source
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// Decode reads a JSON document keeping numbers as json.Number, so that they
+// are written back unchanged after merging.
+func Decode(r io.Reader) (interface{}, error) {
+	var doc interface{}
+
+	d := json.NewDecoder(r)
+	d.UseNumber()
+	if err := d.Decode(&doc); err != nil {
+		return nil, err
+	}
+
+	return doc, nil
+}
+
+// Merge layers documents in order following JSON merge patch (RFC 7386)
+// semantics, every document after the first one is applied as a patch:
+// objects are merged key by key, arrays and scalars replace earlier values
+// and null removes the key. Nulls in the first document are kept as values.
+func Merge(docs ...interface{}) interface{} {
+	if len(docs) == 0 {
+		return nil
+	}
+
+	merged := clone(docs[0])
+	for _, patch := range docs[1:] {
+		merged = mergePatch(merged, patch)
+	}
+
+	return merged
+}
+
+func mergePatch(target, patch interface{}) interface{} {
+	p, ok := patch.(map[string]interface{})
+	if !ok {
+		return clone(patch)
+	}
+
+	t, ok := target.(map[string]interface{})
+	if !ok {
+		t = map[string]interface{}{}
+	}
+
+	for k, v := range p {
+		if v == nil {
+			delete(t, k)
+			continue
+		}
+
+		t[k] = mergePatch(t[k], v)
+	}
+
+	return t
+}
+
+func clone(doc interface{}) interface{} {
+	switch doc := doc.(type) {
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(doc))
+		for k, v := range doc {
+			m[k] = clone(v)
+		}
+		return m
+	case []interface{}:
+		s := make([]interface{}, len(doc))
+		for i, v := range doc {
+			s[i] = clone(v)
+		}
+		return s
+	}
+
+	return doc
+}
@@ -0,0 +1,73 @@
+package source_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/b-b3rn4rd/json2ssm/pkg/source"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMerge(t *testing.T) {
+	tests := map[string]struct {
+		docs     []string
+		expected string
+	}{
+		"single": {
+			docs:     []string{`{"name": "app", "manager": null}`},
+			expected: `{"name": "app", "manager": null}`,
+		},
+		"deep": {
+			docs: []string{
+				`{"db": {"host": "localhost", "port": 5432}, "debug": true}`,
+				`{"db": {"host": "db.prod"}}`,
+				`{"debug": false}`,
+			},
+			expected: `{"db": {"host": "db.prod", "port": 5432}, "debug": false}`,
+		},
+		"arrays are replaced": {
+			docs:     []string{`{"hosts": ["a", "b", "c"]}`, `{"hosts": ["d"]}`},
+			expected: `{"hosts": ["d"]}`,
+		},
+		"null removes key": {
+			docs:     []string{`{"db": {"host": "localhost", "port": 5432}, "cache": {"ttl": 5}}`, `{"db": {"port": null}, "cache": null}`},
+			expected: `{"db": {"host": "localhost"}}`,
+		},
+		"object replaces scalar": {
+			docs:     []string{`{"db": "localhost"}`, `{"db": {"host": "localhost"}}`},
+			expected: `{"db": {"host": "localhost"}}`,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var docs []interface{}
+			for _, d := range tt.docs {
+				doc, err := source.Decode(strings.NewReader(d))
+				assert.NoError(t, err)
+				docs = append(docs, doc)
+			}
+
+			raw, err := json.Marshal(source.Merge(docs...))
+			assert.NoError(t, err)
+			assert.JSONEq(t, tt.expected, string(raw))
+		})
+	}
+}
+
+func TestMergeKeepsDocuments(t *testing.T) {
+	base := map[string]interface{}{"db": map[string]interface{}{"host": "localhost"}}
+	source.Merge(base, map[string]interface{}{"db": map[string]interface{}{"host": "db.prod"}})
+
+	assert.Equal(t, map[string]interface{}{"db": map[string]interface{}{"host": "localhost"}}, base)
+}
+
+func TestDecodeKeepsNumbers(t *testing.T) {
+	doc, err := source.Decode(strings.NewReader(`{"id": 12345678901234567890}`))
+	assert.NoError(t, err)
+
+	raw, err := json.Marshal(doc)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"id":12345678901234567890}`, string(raw))
+}
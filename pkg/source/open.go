@@ -0,0 +1,95 @@
+package source
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+)
+
+// Stdin is the source name used for standard input.
+const Stdin = "-"
+
+var client = &http.Client{Timeout: 30 * time.Second}
+
+// Open returns a reader for a source document, which is either - for stdin,
+// a file:// or http(s):// URL, or a local path. Reading more than maxSize
+// bytes fails, zero disables the limit.
+func Open(spec string, maxSize int64) (io.ReadCloser, error) {
+	r, err := open(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	if maxSize <= 0 {
+		return r, nil
+	}
+
+	name := spec
+	if spec == Stdin {
+		name = "stdin"
+	}
+
+	return &limitedReader{ReadCloser: r, name: name, left: maxSize, max: maxSize}, nil
+}
+
+func open(spec string) (io.ReadCloser, error) {
+	if spec == Stdin {
+		return ioutil.NopCloser(os.Stdin), nil
+	}
+
+	u, err := url.Parse(spec)
+	if err != nil || u.Scheme == "" || len(u.Scheme) == 1 {
+		// not a URL or a windows drive letter
+		return os.Open(spec)
+	}
+
+	switch u.Scheme {
+	case "file":
+		return os.Open(u.Host + u.Path)
+	case "http", "https":
+		resp, err := client.Get(spec)
+		if err != nil {
+			return nil, err
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return nil, fmt.Errorf("can't fetch %s: %s", spec, resp.Status)
+		}
+
+		return resp.Body, nil
+	}
+
+	return nil, fmt.Errorf("unsupported source scheme %s", u.Scheme)
+}
+
+type limitedReader struct {
+	io.ReadCloser
+	name string
+	left int64
+	max  int64
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	if l.left < 0 {
+		return 0, fmt.Errorf("%s is larger than %d bytes", l.name, l.max)
+	}
+
+	// read one byte past the limit to tell a source of exactly max bytes
+	// from a larger one
+	if int64(len(p)) > l.left+1 {
+		p = p[:l.left+1]
+	}
+
+	n, err := l.ReadCloser.Read(p)
+	l.left -= int64(n)
+	if l.left < 0 {
+		return n, fmt.Errorf("%s is larger than %d bytes", l.name, l.max)
+	}
+
+	return n, err
+}
@@ -0,0 +1,67 @@
+package source_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/b-b3rn4rd/json2ssm/pkg/source"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOpen(t *testing.T) {
+	dir, err := ioutil.TempDir("", "source")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "config.json")
+	assert.NoError(t, ioutil.WriteFile(path, []byte(`{"name": "app"}`), 0644))
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/config.json" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"name": "app"}`))
+	}))
+	defer srv.Close()
+
+	tests := map[string]struct {
+		spec    string
+		maxSize int64
+		err     bool
+	}{
+		"path":           {spec: path},
+		"file url":       {spec: "file://" + filepath.ToSlash(path)},
+		"http":           {spec: srv.URL + "/config.json"},
+		"exact size":     {spec: path, maxSize: 15},
+		"too large":      {spec: path, maxSize: 14, err: true},
+		"http too big":   {spec: srv.URL + "/config.json", maxSize: 10, err: true},
+		"http missing":   {spec: srv.URL + "/missing.json", err: true},
+		"missing file":   {spec: filepath.Join(dir, "missing.json"), err: true},
+		"unknown scheme": {spec: "ftp://example.com/config.json", err: true},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			r, err := source.Open(tt.spec, tt.maxSize)
+			if err == nil {
+				defer r.Close()
+				var raw []byte
+				raw, err = ioutil.ReadAll(r)
+				if err == nil {
+					assert.Equal(t, `{"name": "app"}`, string(raw))
+				}
+			}
+
+			if tt.err {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package source
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Dotenv flattens KEY=VALUE files, values can be single quoted as is or
+// double quoted with \n, \t, \" and \\ escapes and may span several lines.
+type Dotenv struct {
+	// Separator splits keys into the parameter hierarchy, e.g. __ turns
+	// DB__HOST into DB/HOST.
+	Separator string
+	// InferTypes converts true, false and numbers to bool and float64.
+	InferTypes bool
+}
+
+func (d *Dotenv) Flatten(r io.Reader) (map[string]interface{}, error) {
+	values := map[string]interface{}{}
+	scanner := bufio.NewScanner(r)
+	n := 0
+
+	for scanner.Scan() {
+		n++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		line = strings.TrimPrefix(line, "export ")
+
+		i := strings.Index(line, "=")
+		if i <= 0 {
+			return nil, fmt.Errorf("line %d: expected KEY=VALUE", n)
+		}
+
+		key := strings.TrimSpace(line[:i])
+		value := strings.TrimSpace(line[i+1:])
+		start := n
+
+		switch {
+		case strings.HasPrefix(value, `"`):
+			// keep reading lines until the closing quote
+			end := closing(value)
+			for ; end < 0; end = closing(value) {
+				if !scanner.Scan() {
+					return nil, fmt.Errorf("line %d: unterminated quoted value", start)
+				}
+				n++
+				value += "\n" + scanner.Text()
+			}
+
+			value = unescape(value[1:end])
+		case strings.HasPrefix(value, "'"):
+			end := strings.Index(value[1:], "'")
+			if end < 0 {
+				return nil, fmt.Errorf("line %d: unterminated quoted value", start)
+			}
+
+			value = value[1 : end+1]
+		default:
+			if i := strings.Index(value, " #"); i >= 0 {
+				value = strings.TrimSpace(value[:i])
+			}
+		}
+
+		entry(values, key, value, d.Separator, d.InferTypes)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return values, nil
+}
+
+// closing returns the index of the quote closing a double quoted value, or -1
+// when the value doesn't have it yet.
+func closing(value string) int {
+	for i := 1; i < len(value); i++ {
+		switch value[i] {
+		case '\\':
+			i++
+		case '"':
+			return i
+		}
+	}
+
+	return -1
+}
+
+func unescape(value string) string {
+	var b strings.Builder
+
+	for i := 0; i < len(value); i++ {
+		if value[i] != '\\' || i+1 == len(value) {
+			b.WriteByte(value[i])
+			continue
+		}
+
+		i++
+		switch value[i] {
+		case 'n':
+			b.WriteByte('\n')
+		case 't':
+			b.WriteByte('\t')
+		case 'r':
+			b.WriteByte('\r')
+		default:
+			b.WriteByte(value[i])
+		}
+	}
+
+	return b.String()
+}
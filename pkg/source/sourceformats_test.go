@@ -0,0 +1,151 @@
+package source_test
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/b-b3rn4rd/json2ssm/pkg/source"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSourceFormats(t *testing.T) {
+	tests := map[string]struct {
+		flattener source.Flattener
+		file      string
+		response  map[string]interface{}
+	}{
+		"dotenv": {
+			flattener: &source.Dotenv{Separator: "__"},
+			file:      "testdata/app.env",
+			response: map[string]interface{}{
+				"DB/HOST": "localhost",
+				"DB/PORT": "5432",
+				"DEBUG":   "true",
+				"ZIP":     "03000",
+				"SINGLE":  `a "quoted" $value`,
+				"SAY":     "a",
+				"IT":      "a",
+				"MULTI":   "line one\nline \"two\"\tend",
+				"EMPTY":   "",
+			},
+		},
+		"dotenv infer types": {
+			flattener: &source.Dotenv{Separator: "__", InferTypes: true},
+			file:      "testdata/app.env",
+			response: map[string]interface{}{
+				"DB/HOST": "localhost",
+				"DB/PORT": float64(5432),
+				"DEBUG":   true,
+				"ZIP":     "03000",
+				"SINGLE":  `a "quoted" $value`,
+				"SAY":     "a",
+				"IT":      "a",
+				"MULTI":   "line one\nline \"two\"\tend",
+				"EMPTY":   "",
+			},
+		},
+		"ini": {
+			flattener: &source.INI{Separator: ".", InferTypes: true},
+			file:      "testdata/app.ini",
+			response: map[string]interface{}{
+				"name":            "app",
+				"db/primary/host": "localhost",
+				"db/primary/port": float64(5432),
+				"cache/enabled":   false,
+			},
+		},
+		"ini without separator": {
+			flattener: &source.INI{},
+			file:      "testdata/app.ini",
+			response: map[string]interface{}{
+				"name":            "app",
+				"db.primary/host": "localhost",
+				"db.primary/port": "5432",
+				"cache/enabled":   "false",
+			},
+		},
+		"properties": {
+			flattener: &source.Properties{Separator: "."},
+			file:      "testdata/app.properties",
+			response: map[string]interface{}{
+				"db/host":           "localhost",
+				"db/port":           "5432",
+				"app/name":          "My Application",
+				"app/greeting":      "café",
+				"path=with:escapes": "value",
+				"debug":             "",
+			},
+		},
+		"toml": {
+			flattener: &source.TOML{},
+			file:      "testdata/app.toml",
+			response: map[string]interface{}{
+				"name":           "app",
+				"replicas":       int64(3),
+				"ratio":          0.5,
+				"released":       time.Date(1979, 5, 27, 7, 32, 0, 0, time.UTC),
+				"day":            "1979-05-27",
+				"tags/0":         "web",
+				"tags/1":         "api",
+				"db/host":        "localhost",
+				"db/port":        int64(5432),
+				"servers/0/name": "a",
+				"servers/1/name": "b",
+			},
+		},
+		"hcl": {
+			flattener: &source.HCL{},
+			file:      "testdata/app.hcl",
+			response: map[string]interface{}{
+				"name":             "app",
+				"ratio":            0.5,
+				"enabled":          true,
+				"tags/0":           "web",
+				"tags/1":           "api",
+				"db/host":          "localhost",
+				"db/port":          int64(5432),
+				"service/web/port": int64(80),
+				"service/api/port": int64(81),
+				"rule/0/allow":     "a",
+				"rule/1/allow":     "b",
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			r, err := os.Open(tt.file)
+			assert.NoError(t, err)
+			defer r.Close()
+
+			values, err := tt.flattener.Flatten(r)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.response, values)
+		})
+	}
+}
+
+func TestSourceFormatsErrors(t *testing.T) {
+	tests := map[string]struct {
+		flattener source.Flattener
+		doc       string
+		err       string
+	}{
+		"dotenv without value": {&source.Dotenv{}, "A=1\nB", "line 2: expected KEY=VALUE"},
+		"dotenv unterminated":  {&source.Dotenv{}, "A=\"one\ntwo", "line 1: unterminated quoted value"},
+		"ini section":          {&source.INI{}, "[db\nhost=x", "line 1: unterminated section"},
+		"ini without value":    {&source.INI{}, "[db]\nhost", "line 2: expected key = value"},
+		"properties escape":    {&source.Properties{}, "a=\\u00zz", "line 1: invalid unicode escape \\u00zz"},
+		"toml":                 {&source.TOML{}, "[db\nhost = 1", "Near line 1 (last key parsed ''): expected '.' or ']' to end table name, but got '\\n' instead"},
+		"hcl":                  {&source.HCL{}, "db {\nhost = 1", "At 2:12: object expected closing RBRACE got: EOF"},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := tt.flattener.Flatten(strings.NewReader(tt.doc))
+			assert.EqualError(t, err, tt.err)
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package source
+
+import (
+	"io"
+	"io/ioutil"
+
+	"github.com/hashicorp/hcl"
+)
+
+// HCL flattens HCL documents, blocks and their labels become levels of the
+// parameter hierarchy, e.g. service "web" { port = 80 } turns into
+// service/web/port. Repeated blocks without labels are kept as arrays and
+// integers are kept as int64.
+type HCL struct{}
+
+func (f *HCL) Flatten(r io.Reader) (map[string]interface{}, error) {
+	raw, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc map[string]interface{}
+	if err := hcl.Unmarshal(raw, &doc); err != nil {
+		return nil, err
+	}
+
+	values := map[string]interface{}{}
+	flattenTree(values, "", hclValue(doc))
+
+	return values, nil
+}
+
+// hclValue merges the list of objects every block is decoded into, unless
+// keys of the objects overlap, which happens for repeated unlabeled blocks.
+func hclValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case int:
+		return int64(v)
+	case map[string]interface{}:
+		for k, item := range v {
+			v[k] = hclValue(item)
+		}
+		return v
+	case []interface{}:
+		for i, item := range v {
+			v[i] = hclValue(item)
+		}
+		return v
+	case []map[string]interface{}:
+		merged := map[string]interface{}{}
+		for _, item := range v {
+			for k, value := range item {
+				if _, ok := merged[k]; ok {
+					items := make([]interface{}, len(v))
+					for i, item := range v {
+						items[i] = hclValue(item)
+					}
+					return items
+				}
+				merged[k] = value
+			}
+		}
+		return hclValue(merged)
+	}
+
+	return v
+}
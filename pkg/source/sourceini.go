@@ -0,0 +1,75 @@
+package source
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// INI flattens [section] key = value files, keys are prefixed with their
+// section, keys before the first section are kept at the root.
+type INI struct {
+	// Separator splits section names and keys into the parameter hierarchy,
+	// e.g. . turns [db.primary] host into db/primary/host.
+	Separator string
+	// InferTypes converts true, false and numbers to bool and float64.
+	InferTypes bool
+}
+
+func (f *INI) Flatten(r io.Reader) (map[string]interface{}, error) {
+	values := map[string]interface{}{}
+	scanner := bufio.NewScanner(r)
+	section := ""
+	n := 0
+
+	for scanner.Scan() {
+		n++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, ";") || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") {
+			if !strings.HasSuffix(line, "]") {
+				return nil, fmt.Errorf("line %d: unterminated section", n)
+			}
+
+			section = strings.TrimSpace(line[1 : len(line)-1])
+			continue
+		}
+
+		i := strings.IndexAny(line, "=:")
+		if i <= 0 {
+			return nil, fmt.Errorf("line %d: expected key = value", n)
+		}
+
+		key := strings.TrimSpace(line[:i])
+		if section != "" {
+			key = section + f.separator() + key
+		}
+
+		value := strings.TrimSpace(line[i+1:])
+		if len(value) > 1 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+
+		entry(values, key, value, f.Separator, f.InferTypes)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return values, nil
+}
+
+// separator joins a section with its keys, without a separator sections are
+// still a level of the hierarchy.
+func (f *INI) separator() string {
+	if f.Separator == "" {
+		return "/"
+	}
+
+	return f.Separator
+}
@@ -0,0 +1,140 @@
+package source
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Properties flattens Java .properties files: key=value, key: value and
+// key value pairs, ! and # comments, lines continued with a trailing \ and
+// \uXXXX escapes.
+type Properties struct {
+	// Separator splits keys into the parameter hierarchy, e.g. . turns
+	// db.host into db/host.
+	Separator string
+	// InferTypes converts true, false and numbers to bool and float64.
+	InferTypes bool
+}
+
+func (p *Properties) Flatten(r io.Reader) (map[string]interface{}, error) {
+	values := map[string]interface{}{}
+	scanner := bufio.NewScanner(r)
+	n := 0
+
+	for scanner.Scan() {
+		n++
+		line := strings.TrimLeft(scanner.Text(), " \t\f")
+		if line == "" || line[0] == '#' || line[0] == '!' {
+			continue
+		}
+
+		for continued(line) {
+			line = line[:len(line)-1]
+			if !scanner.Scan() {
+				break
+			}
+			n++
+			line += strings.TrimLeft(scanner.Text(), " \t\f")
+		}
+
+		key, value, err := splitProperty(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %s", n, err)
+		}
+
+		entry(values, key, value, p.Separator, p.InferTypes)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return values, nil
+}
+
+// continued reports whether the line ends with an odd number of backslashes.
+func continued(line string) bool {
+	count := 0
+	for i := len(line) - 1; i >= 0 && line[i] == '\\'; i-- {
+		count++
+	}
+
+	return count%2 == 1
+}
+
+// splitProperty splits a logical line at the first unescaped =, : or
+// whitespace and unescapes both parts.
+func splitProperty(line string) (string, string, error) {
+	end := len(line)
+	for i := 0; i < len(line); i++ {
+		if line[i] == '\\' {
+			i++
+			continue
+		}
+
+		if strings.IndexByte("=: \t\f", line[i]) >= 0 {
+			end = i
+			break
+		}
+	}
+
+	key := line[:end]
+	rest := strings.TrimLeft(line[end:], " \t\f")
+	if rest != "" && (rest[0] == '=' || rest[0] == ':') {
+		rest = strings.TrimLeft(rest[1:], " \t\f")
+	}
+
+	k, err := unescapeProperty(key)
+	if err != nil {
+		return "", "", err
+	}
+
+	v, err := unescapeProperty(rest)
+	if err != nil {
+		return "", "", err
+	}
+
+	return k, v, nil
+}
+
+func unescapeProperty(s string) (string, error) {
+	var b strings.Builder
+
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 == len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+
+		i++
+		switch s[i] {
+		case 't':
+			b.WriteByte('\t')
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case 'f':
+			b.WriteByte('\f')
+		case 'u':
+			if i+5 > len(s) {
+				return "", fmt.Errorf("invalid unicode escape \\%s", s[i:])
+			}
+
+			code, err := strconv.ParseUint(s[i+1:i+5], 16, 16)
+			if err != nil {
+				return "", fmt.Errorf("invalid unicode escape \\%s", s[i:i+5])
+			}
+
+			b.WriteRune(rune(code))
+			i += 4
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+
+	return b.String(), nil
+}
@@ -0,0 +1,48 @@
+package source
+
+import (
+	"io"
+	"time"
+
+	"github.com/BurntSushi/toml"
+)
+
+// TOML flattens TOML documents, tables become levels of the parameter
+// hierarchy. Integers are kept as int64 and offset datetimes as time.Time so
+// they are stored with their own type, local dates and times are stored as
+// written.
+type TOML struct{}
+
+func (f *TOML) Flatten(r io.Reader) (map[string]interface{}, error) {
+	var doc map[string]interface{}
+	if _, err := toml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, err
+	}
+
+	values := map[string]interface{}{}
+	flattenTree(values, "", doc)
+
+	for k, v := range values {
+		if t, ok := v.(time.Time); ok {
+			values[k] = tomlTime(t)
+		}
+	}
+
+	return values, nil
+}
+
+// tomlTime formats local dates and times, which have no offset and can't be
+// converted back to a time.Time, as they are written in TOML. The decoder
+// marks them with locations named after their TOML type.
+func tomlTime(t time.Time) interface{} {
+	switch t.Location().String() {
+	case "date-local":
+		return t.Format("2006-01-02")
+	case "time-local":
+		return t.Format("15:04:05.999999999")
+	case "datetime-local":
+		return t.Format("2006-01-02T15:04:05.999999999")
+	}
+
+	return t
+}
@@ -0,0 +1,146 @@
+package source
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// Record is a single leaf of a flattened document, Type is the type tag the
+// value is stored with.
+type Record struct {
+	Key   string
+	Value interface{}
+	Type  string
+}
+
+// Stream flattens a JSON document token by token and sends its leaves to
+// records as they are read, only the current key is kept in memory. Keys and
+// values are the same as JSON.Flatten returns. The channel is closed when
+// Stream returns, it stops early when the context is done.
+func Stream(ctx context.Context, r io.Reader, records chan<- Record) error {
+	defer close(records)
+
+	dec := json.NewDecoder(r)
+
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if _, ok := tok.(json.Delim); !ok {
+		return fmt.Errorf("expected an object or an array, got %v", tok)
+	}
+
+	s := &stream{ctx: ctx, dec: dec, records: records}
+	if err := s.value(tok, ""); err != nil {
+		return err
+	}
+
+	if _, err := dec.Token(); err != io.EOF {
+		return fmt.Errorf("unexpected data after the document")
+	}
+
+	return nil
+}
+
+// StreamJSON is a Flattener built on Stream, the document is never held in
+// memory, only the flattened values are.
+type StreamJSON struct{}
+
+func (f *StreamJSON) Flatten(r io.Reader) (map[string]interface{}, error) {
+	records := make(chan Record)
+	errs := make(chan error, 1)
+
+	go func() {
+		errs <- Stream(context.Background(), r, records)
+	}()
+
+	values := map[string]interface{}{}
+	for rec := range records {
+		values[rec.Key] = rec.Value
+	}
+
+	if err := <-errs; err != nil {
+		return nil, err
+	}
+
+	return values, nil
+}
+
+type stream struct {
+	ctx     context.Context
+	dec     *json.Decoder
+	records chan<- Record
+}
+
+func (s *stream) value(tok json.Token, key string) error {
+	join := func(name string) string {
+		if key == "" {
+			return name
+		}
+
+		return key + "/" + name
+	}
+
+	switch tok {
+	case json.Delim('{'):
+		for s.dec.More() {
+			name, err := s.dec.Token()
+			if err != nil {
+				return err
+			}
+
+			if err := s.next(join(name.(string))); err != nil {
+				return err
+			}
+		}
+
+		_, err := s.dec.Token()
+		return err
+
+	case json.Delim('['):
+		for i := 0; s.dec.More(); i++ {
+			if err := s.next(join(strconv.Itoa(i))); err != nil {
+				return err
+			}
+		}
+
+		_, err := s.dec.Token()
+		return err
+	}
+
+	return s.send(Record{Key: key, Value: tok, Type: recordType(tok)})
+}
+
+func (s *stream) next(key string) error {
+	tok, err := s.dec.Token()
+	if err != nil {
+		return err
+	}
+
+	return s.value(tok, key)
+}
+
+func (s *stream) send(rec Record) error {
+	select {
+	case s.records <- rec:
+		return nil
+	case <-s.ctx.Done():
+		return s.ctx.Err()
+	}
+}
+
+func recordType(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "nil"
+	case bool:
+		return "bool"
+	case float64:
+		return "float64"
+	}
+
+	return "string"
+}
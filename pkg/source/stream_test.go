@@ -0,0 +1,78 @@
+package source_test
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/b-b3rn4rd/json2ssm/pkg/source"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStreamMatchesFlatten(t *testing.T) {
+	for _, file := range []string{"testdata/simplemap.json", "testdata/simpleslice.json", "testdata/mapinslice.json"} {
+		t.Run(file, func(t *testing.T) {
+			r, err := os.Open(file)
+			assert.NoError(t, err)
+			defer r.Close()
+
+			expected, err := (&source.JSON{}).Flatten(r)
+			assert.NoError(t, err)
+
+			_, err = r.Seek(0, 0)
+			assert.NoError(t, err)
+
+			values, err := (&source.StreamJSON{}).Flatten(r)
+			assert.NoError(t, err)
+			assert.Equal(t, expected, values)
+		})
+	}
+}
+
+func TestStreamRecords(t *testing.T) {
+	records := make(chan source.Record)
+	errs := make(chan error, 1)
+
+	go func() {
+		errs <- source.Stream(context.Background(), strings.NewReader(`{"a": {"b": [true, null]}, "c": 1.5, "d": "x", "e": {}}`), records)
+	}()
+
+	var got []source.Record
+	for rec := range records {
+		got = append(got, rec)
+	}
+
+	assert.NoError(t, <-errs)
+	assert.Equal(t, []source.Record{
+		{Key: "a/b/0", Value: true, Type: "bool"},
+		{Key: "a/b/1", Value: nil, Type: "nil"},
+		{Key: "c", Value: 1.5, Type: "float64"},
+		{Key: "d", Value: "x", Type: "string"},
+	}, got)
+}
+
+func TestStreamErrors(t *testing.T) {
+	tests := map[string]struct {
+		doc string
+		err string
+	}{
+		"scalar":   {`"x"`, "expected an object or an array, got x"},
+		"trailing": {`{"a": 1} {}`, "unexpected data after the document"},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := (&source.StreamJSON{}).Flatten(strings.NewReader(tt.doc))
+			assert.EqualError(t, err, tt.err)
+		})
+	}
+}
+
+func TestStreamCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := source.Stream(ctx, strings.NewReader(`{"a": 1}`), make(chan source.Record))
+	assert.Equal(t, context.Canceled, err)
+}
@@ -0,0 +1,25 @@
+name    = "app"
+ratio   = 0.5
+enabled = true
+tags    = ["web", "api"]
+
+db {
+  host = "localhost"
+  port = 5432
+}
+
+service "web" {
+  port = 80
+}
+
+service "api" {
+  port = 81
+}
+
+rule {
+  allow = "a"
+}
+
+rule {
+  allow = "b"
+}
@@ -0,0 +1,35 @@
+package source
+
+import (
+	"strconv"
+)
+
+// flattenTree adds the leaves of a decoded document to values, keys are
+// joined with / and array items are keyed by their index, as JSON documents
+// are flattened.
+func flattenTree(values map[string]interface{}, key string, v interface{}) {
+	join := func(name string) string {
+		if key == "" {
+			return name
+		}
+
+		return key + "/" + name
+	}
+
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, item := range v {
+			flattenTree(values, join(k), item)
+		}
+	case []map[string]interface{}:
+		for i, item := range v {
+			flattenTree(values, join(strconv.Itoa(i)), item)
+		}
+	case []interface{}:
+		for i, item := range v {
+			flattenTree(values, join(strconv.Itoa(i)), item)
+		}
+	default:
+		values[key] = v
+	}
+}
@@ -0,0 +1,343 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	durationType = reflect.TypeOf(time.Duration(0))
+	timeType     = reflect.TypeOf(time.Time{})
+)
+
+// ValidationError lists every required key that is missing and every value
+// that can't be decoded into its field.
+type ValidationError struct {
+	Missing []string
+	Invalid []string
+}
+
+func (e *ValidationError) Error() string {
+	var parts []string
+	if len(e.Missing) > 0 {
+		parts = append(parts, fmt.Sprintf("missing required keys: %s", strings.Join(e.Missing, ", ")))
+	}
+	if len(e.Invalid) > 0 {
+		parts = append(parts, fmt.Sprintf("invalid values: %s", strings.Join(e.Invalid, ", ")))
+	}
+
+	return strings.Join(parts, "; ")
+}
+
+// ExportInto exports the decrypted tree under the path and decodes it into v,
+// see Decode.
+func (s *SSMStorage) ExportInto(ctx context.Context, path string, v interface{}) error {
+	tree, err := s.ExportContext(ctx, path, ExportOptions{Decrypt: true})
+	if err != nil {
+		return err
+	}
+
+	return Decode(tree, v)
+}
+
+// Decode fills the struct pointed to by v from an exported tree. Fields are
+// matched by the name in the ssm tag or by the case-insensitive field name,
+// tag options are required and default=value, e.g. `ssm:"port,default=8080"`.
+// Scalars stored as strings are parsed into numeric, bool, time.Duration and
+// time.Time (RFC 3339) fields.
+func Decode(tree interface{}, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("can't decode into %T, a pointer to a struct is required", v)
+	}
+
+	if tree == nil {
+		tree = map[string]interface{}{}
+	}
+
+	errs := &ValidationError{}
+	decodeValue(rv.Elem(), tree, "", errs)
+
+	if len(errs.Missing) == 0 && len(errs.Invalid) == 0 {
+		return nil
+	}
+
+	sort.Strings(errs.Missing)
+	sort.Strings(errs.Invalid)
+
+	return errs
+}
+
+type fieldTag struct {
+	name     string
+	required bool
+	def      *string
+}
+
+func parseTag(f reflect.StructField) fieldTag {
+	tag := fieldTag{name: f.Name}
+
+	parts := strings.Split(f.Tag.Get("ssm"), ",")
+	if parts[0] != "" {
+		tag.name = parts[0]
+	}
+
+	for i := 1; i < len(parts); i++ {
+		switch {
+		case parts[i] == "required":
+			tag.required = true
+		case strings.HasPrefix(parts[i], "default="):
+			// the default value may contain commas
+			def := strings.Join(append([]string{strings.TrimPrefix(parts[i], "default=")}, parts[i+1:]...), ",")
+			tag.def = &def
+			i = len(parts)
+		}
+	}
+
+	return tag
+}
+
+func lookup(m map[string]interface{}, name string) (interface{}, bool) {
+	if v, ok := m[name]; ok {
+		return v, true
+	}
+
+	for k, v := range m {
+		if strings.EqualFold(k, name) {
+			return v, true
+		}
+	}
+
+	return nil, false
+}
+
+func join(key, name string) string {
+	if key == "" {
+		return name
+	}
+
+	return key + "/" + name
+}
+
+func decodeStruct(rv reflect.Value, m map[string]interface{}, key string, errs *ValidationError) {
+	rt := rv.Type()
+
+	for i := 0; i < rt.NumField(); i++ {
+		f := rt.Field(i)
+		if f.PkgPath != "" || f.Tag.Get("ssm") == "-" {
+			continue
+		}
+
+		tag := parseTag(f)
+		fkey := join(key, tag.name)
+
+		value, ok := lookup(m, tag.name)
+		if !ok || value == nil {
+			switch {
+			case tag.def != nil:
+				value = *tag.def
+			case tag.required:
+				errs.Missing = append(errs.Missing, fkey)
+				continue
+			case f.Type.Kind() == reflect.Struct && f.Type != timeType:
+				// nested structs may still have required fields or defaults
+				value = map[string]interface{}{}
+			default:
+				continue
+			}
+		}
+
+		decodeValue(rv.Field(i), value, fkey, errs)
+	}
+}
+
+func decodeValue(rv reflect.Value, value interface{}, key string, errs *ValidationError) {
+	if value == nil {
+		return
+	}
+
+	invalid := func(format string, args ...interface{}) {
+		errs.Invalid = append(errs.Invalid, fmt.Sprintf("%s: %s", key, fmt.Sprintf(format, args...)))
+	}
+
+	if rv.Type() == durationType {
+		s, ok := value.(string)
+		if !ok {
+			invalid("duration must be a string like 30s, got %v", value)
+			return
+		}
+
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			invalid("%s", err)
+			return
+		}
+
+		rv.SetInt(int64(d))
+		return
+	}
+
+	if rv.Type() == timeType {
+		if t, ok := value.(time.Time); ok {
+			rv.Set(reflect.ValueOf(t))
+			return
+		}
+
+		t, err := time.Parse(time.RFC3339Nano, stringValue(value))
+		if err != nil {
+			invalid("%v is not an RFC 3339 datetime", value)
+			return
+		}
+
+		rv.Set(reflect.ValueOf(t))
+		return
+	}
+
+	switch rv.Kind() {
+	case reflect.Ptr:
+		if rv.IsNil() {
+			rv.Set(reflect.New(rv.Type().Elem()))
+		}
+		decodeValue(rv.Elem(), value, key, errs)
+
+	case reflect.Interface:
+		rv.Set(reflect.ValueOf(value))
+
+	case reflect.Struct:
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			invalid("expected an object, got %v", value)
+			return
+		}
+		decodeStruct(rv, m, key, errs)
+
+	case reflect.Map:
+		m, ok := value.(map[string]interface{})
+		if !ok || rv.Type().Key().Kind() != reflect.String {
+			invalid("expected an object, got %v", value)
+			return
+		}
+
+		out := reflect.MakeMap(rv.Type())
+		for k, v := range m {
+			elem := reflect.New(rv.Type().Elem()).Elem()
+			decodeValue(elem, v, join(key, k), errs)
+			out.SetMapIndex(reflect.ValueOf(k).Convert(rv.Type().Key()), elem)
+		}
+		rv.Set(out)
+
+	case reflect.Slice:
+		items, ok := value.([]interface{})
+		if !ok {
+			invalid("expected an array, got %v", value)
+			return
+		}
+
+		out := reflect.MakeSlice(rv.Type(), len(items), len(items))
+		for i, v := range items {
+			decodeValue(out.Index(i), v, join(key, strconv.Itoa(i)), errs)
+		}
+		rv.Set(out)
+
+	case reflect.String:
+		rv.SetString(stringValue(value))
+
+	case reflect.Bool:
+		switch v := value.(type) {
+		case bool:
+			rv.SetBool(v)
+		case string:
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				invalid("%s is not a bool", v)
+				return
+			}
+			rv.SetBool(b)
+		default:
+			invalid("%v is not a bool", value)
+		}
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, ok := intValue(value, rv.Type().Bits())
+		if !ok || rv.OverflowInt(n) {
+			invalid("%v is not an integer", value)
+			return
+		}
+		rv.SetInt(n)
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, ok := uintValue(value, rv.Type().Bits())
+		if !ok || rv.OverflowUint(n) {
+			invalid("%v is not an unsigned integer", value)
+			return
+		}
+		rv.SetUint(n)
+
+	case reflect.Float32, reflect.Float64:
+		n, ok := floatValue(value, rv.Type().Bits())
+		if !ok || rv.OverflowFloat(n) {
+			invalid("%v is not a number", value)
+			return
+		}
+		rv.SetFloat(n)
+
+	default:
+		invalid("unsupported field type %s", rv.Type())
+	}
+}
+
+// intValue converts exported numbers, only strings are parsed, a float64 must
+// be integral and fit into an int64.
+func intValue(value interface{}, bits int) (int64, bool) {
+	switch v := value.(type) {
+	case int64:
+		return v, true
+	case float64:
+		if v != math.Trunc(v) || v < math.MinInt64 || v >= math.MaxInt64 {
+			return 0, false
+		}
+		return int64(v), true
+	case string:
+		n, err := strconv.ParseInt(v, 10, bits)
+		return n, err == nil
+	}
+
+	return 0, false
+}
+
+func uintValue(value interface{}, bits int) (uint64, bool) {
+	switch v := value.(type) {
+	case int64:
+		return uint64(v), v >= 0
+	case float64:
+		if v != math.Trunc(v) || v < 0 || v >= math.MaxUint64 {
+			return 0, false
+		}
+		return uint64(v), true
+	case string:
+		n, err := strconv.ParseUint(v, 10, bits)
+		return n, err == nil
+	}
+
+	return 0, false
+}
+
+func floatValue(value interface{}, bits int) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case int64:
+		return float64(v), true
+	case string:
+		n, err := strconv.ParseFloat(v, bits)
+		return n, err == nil
+	}
+
+	return 0, false
+}
@@ -0,0 +1,173 @@
+package storage_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/b-b3rn4rd/json2ssm/pkg/fakessm"
+	"github.com/b-b3rn4rd/json2ssm/pkg/storage"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+)
+
+type database struct {
+	Host     string `ssm:"host,required"`
+	Port     int    `ssm:"port,default=5432"`
+	Password string `ssm:"password,required"`
+}
+
+type config struct {
+	Name     string
+	Debug    bool          `ssm:"debug"`
+	Ratio    float64       `ssm:"ratio"`
+	Timeout  time.Duration `ssm:"timeout,default=30s"`
+	Released time.Time     `ssm:"released"`
+	Hosts    []string      `ssm:"hosts"`
+	Labels   map[string]string
+	DB       database  `ssm:"db"`
+	Cache    *database `ssm:"cache"`
+	Internal string    `ssm:"-"`
+}
+
+func TestDecode(t *testing.T) {
+	tree := map[string]interface{}{
+		"name":     "app",
+		"debug":    "true",
+		"ratio":    float64(0.5),
+		"released": "1979-05-27T07:32:00Z",
+		"hosts":    []interface{}{"a", "b"},
+		"labels":   map[string]interface{}{"team": "core"},
+		"db": map[string]interface{}{
+			"host":     "localhost",
+			"port":     "5433",
+			"password": "secret",
+		},
+		"Internal": "ignored",
+	}
+
+	var cfg config
+	err := storage.Decode(tree, &cfg)
+
+	assert.NoError(t, err)
+	assert.Equal(t, config{
+		Name:     "app",
+		Debug:    true,
+		Ratio:    0.5,
+		Timeout:  30 * time.Second,
+		Released: time.Date(1979, 5, 27, 7, 32, 0, 0, time.UTC),
+		Hosts:    []string{"a", "b"},
+		Labels:   map[string]string{"team": "core"},
+		DB:       database{Host: "localhost", Port: 5433, Password: "secret"},
+	}, cfg)
+}
+
+func TestDecodeValidation(t *testing.T) {
+	tree := map[string]interface{}{
+		"timeout": "soon",
+		"debug":   "maybe",
+		"cache":   map[string]interface{}{"port": "x"},
+	}
+
+	var cfg config
+	err := storage.Decode(tree, &cfg)
+
+	assert.Equal(t, &storage.ValidationError{
+		Missing: []string{"cache/host", "cache/password", "db/host", "db/password"},
+		Invalid: []string{
+			"cache/port: x is not an integer",
+			"debug: maybe is not a bool",
+			`timeout: time: invalid duration "soon"`,
+		},
+	}, err)
+}
+
+type limits struct {
+	Count int     `ssm:"count"`
+	Size  uint32  `ssm:"size"`
+	Ratio float32 `ssm:"ratio"`
+}
+
+func TestDecodeNumbers(t *testing.T) {
+	tests := []struct {
+		name  string
+		tree  map[string]interface{}
+		want  limits
+		error string
+	}{
+		{
+			name: "exported numbers",
+			tree: map[string]interface{}{"count": float64(1234567), "size": float64(3e6), "ratio": float64(1e6)},
+			want: limits{Count: 1234567, Size: 3000000, Ratio: 1e6},
+		},
+		{
+			name: "int64",
+			tree: map[string]interface{}{"count": int64(9007199254740993), "size": int64(7), "ratio": int64(2)},
+			want: limits{Count: 9007199254740993, Size: 7, Ratio: 2},
+		},
+		{
+			name: "strings",
+			tree: map[string]interface{}{"count": "1234567", "size": "42", "ratio": "0.25"},
+			want: limits{Count: 1234567, Size: 42, Ratio: 0.25},
+		},
+		{
+			name:  "fraction",
+			tree:  map[string]interface{}{"count": float64(1.5)},
+			error: "invalid values: count: 1.5 is not an integer",
+		},
+		{
+			name:  "overflow",
+			tree:  map[string]interface{}{"size": float64(1e10)},
+			error: "invalid values: size: 1e+10 is not an unsigned integer",
+		},
+		{
+			name:  "negative",
+			tree:  map[string]interface{}{"size": int64(-1)},
+			error: "invalid values: size: -1 is not an unsigned integer",
+		},
+		{
+			name:  "bool",
+			tree:  map[string]interface{}{"count": true},
+			error: "invalid values: count: true is not an integer",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var got limits
+			err := storage.Decode(test.tree, &got)
+
+			if test.error != "" {
+				assert.EqualError(t, err, test.error)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+func TestDecodeRequiresStructPointer(t *testing.T) {
+	var cfg config
+	assert.Error(t, storage.Decode(map[string]interface{}{}, cfg))
+}
+
+func TestExportInto(t *testing.T) {
+	logger, _ := test.NewNullLogger()
+	str := storage.New(fakessm.New(), logger)
+
+	_, err := str.Import(map[string]interface{}{
+		"app/db/host":     "localhost",
+		"app/db/password": "secret",
+		"app/timeout":     "1m",
+	}, "", true)
+	assert.NoError(t, err)
+
+	var cfg config
+	err = str.ExportInto(context.Background(), "/app", &cfg)
+
+	assert.NoError(t, err)
+	assert.Equal(t, database{Host: "localhost", Port: 5432, Password: "secret"}, cfg.DB)
+	assert.Equal(t, time.Minute, cfg.Timeout)
+}
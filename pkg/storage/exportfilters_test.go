@@ -0,0 +1,84 @@
+package storage_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/b-b3rn4rd/json2ssm/pkg/fakessm"
+	"github.com/b-b3rn4rd/json2ssm/pkg/storage"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExportFilters(t *testing.T) {
+	svc := fakessm.New()
+	logger, _ := test.NewNullLogger()
+	str := storage.New(svc, logger)
+
+	_, err := str.Import(map[string]interface{}{
+		"app/name":    "json2ssm",
+		"app/port":    float64(8080),
+		"app/db/host": "localhost",
+		"app/db/user": "admin",
+	}, "", false)
+	assert.NoError(t, err)
+
+	_, err = svc.PutParameter(&ssm.PutParameterInput{
+		Name:  aws.String("/app/db/password"),
+		Value: aws.String("secret"),
+		Type:  aws.String(ssm.ParameterTypeSecureString),
+	})
+	assert.NoError(t, err)
+
+	tests := map[string]struct {
+		opts     storage.ExportOptions
+		expected map[string]interface{}
+		err      string
+	}{
+		"non-recursive": {
+			opts:     storage.ExportOptions{NonRecursive: true},
+			expected: map[string]interface{}{"name": "json2ssm", "port": float64(8080)},
+		},
+		"include": {
+			opts:     storage.ExportOptions{Include: []string{"db"}, Decrypt: true},
+			expected: map[string]interface{}{"db/host": "localhost", "db/user": "admin", "db/password": "secret"},
+		},
+		"include and exclude": {
+			opts:     storage.ExportOptions{Include: []string{"db/*", "name"}, Exclude: []string{"*/pass*"}},
+			expected: map[string]interface{}{"name": "json2ssm", "db/host": "localhost", "db/user": "admin"},
+		},
+		"type filter": {
+			opts: storage.ExportOptions{Filters: []*ssm.ParameterStringFilter{{
+				Key:    aws.String("Type"),
+				Values: aws.StringSlice([]string{ssm.ParameterTypeSecureString}),
+			}}, Decrypt: true},
+			expected: map[string]interface{}{"db/password": "secret"},
+		},
+		"tag filter": {
+			opts: storage.ExportOptions{Filters: []*ssm.ParameterStringFilter{{
+				Key:    aws.String("tag:type"),
+				Values: aws.StringSlice([]string{"float64"}),
+			}}},
+			expected: map[string]interface{}{"port": float64(8080)},
+		},
+		"invalid pattern": {
+			opts: storage.ExportOptions{Exclude: []string{"[db"}},
+			err:  "invalid pattern [db: syntax error in pattern",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			values, _, err := str.FlattenContext(context.Background(), "/app", tt.opts)
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, values)
+		})
+	}
+}
@@ -0,0 +1,129 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/b-b3rn4rd/json2ssm/pkg/atomicfile"
+)
+
+var _ Storage = &FileStorage{}
+
+// FileStorage keeps parameters in a local JSON file as a flat map of
+// parameter names to values, it is meant for local development without AWS.
+type FileStorage struct {
+	path string
+	mx   sync.Mutex
+}
+
+func NewFile(path string) *FileStorage {
+	return &FileStorage{path: path}
+}
+
+// Export ignores decrypt, values are stored as is.
+func (s *FileStorage) Export(path string, decrypt bool) (interface{}, error) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	params, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := strings.TrimSuffix(path, "/") + "/"
+	values := map[string]interface{}{}
+
+	for k, v := range params {
+		if strings.HasPrefix(k, prefix) {
+			values[strings.TrimPrefix(k, prefix)] = v
+		}
+	}
+
+	return unflattern(values)
+}
+
+// Import ignores msg and encrypt, the file has no room for metadata.
+func (s *FileStorage) Import(values map[string]interface{}, msg string, encrypt bool) (int, error) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	params, err := s.load()
+	if err != nil {
+		return 0, err
+	}
+
+	for k, v := range values {
+		params[fmt.Sprintf("/%s", k)] = v
+	}
+
+	return len(values), s.save(params)
+}
+
+func (s *FileStorage) Delete(values map[string]interface{}) (int, error) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	params, err := s.load()
+	if err != nil {
+		return 0, err
+	}
+
+	for k := range values {
+		delete(params, fmt.Sprintf("/%s", k))
+	}
+
+	return len(values), s.save(params)
+}
+
+func (s *FileStorage) DeletePath(path string) (int, error) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	params, err := s.load()
+	if err != nil {
+		return 0, err
+	}
+
+	prefix := strings.TrimSuffix(path, "/") + "/"
+	total := 0
+
+	for k := range params {
+		if strings.HasPrefix(k, prefix) {
+			delete(params, k)
+			total++
+		}
+	}
+
+	return total, s.save(params)
+}
+
+func (s *FileStorage) load() (map[string]interface{}, error) {
+	params := map[string]interface{}{}
+
+	raw, err := ioutil.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return params, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err = json.Unmarshal(raw, &params); err != nil {
+		return nil, fmt.Errorf("can't parse %s: %s", s.path, err)
+	}
+
+	return params, nil
+}
+
+func (s *FileStorage) save(params map[string]interface{}) error {
+	raw, err := json.MarshalIndent(params, "", " ")
+	if err != nil {
+		return err
+	}
+
+	return atomicfile.Write(s.path, raw, 0600)
+}
@@ -0,0 +1,58 @@
+package storage_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/b-b3rn4rd/json2ssm/pkg/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileStorage(t *testing.T) {
+	dir, err := ioutil.TempDir("", "storage")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	str := storage.NewFile(filepath.Join(dir, "params.json"))
+
+	total, err := str.Import(map[string]interface{}{
+		"app/name":    "bernard",
+		"app/port":    float64(8080),
+		"app/debug":   true,
+		"app/hosts/0": "a",
+		"app/hosts/1": "b",
+		"other/name":  "keith",
+	}, "", false)
+	assert.NoError(t, err)
+	assert.Equal(t, 6, total)
+
+	r, err := str.Export("/app", false)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"name":  "bernard",
+		"port":  float64(8080),
+		"debug": true,
+		"hosts": []interface{}{"a", "b"},
+	}, r)
+
+	total, err = str.Delete(map[string]interface{}{"app/debug": true})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, total)
+
+	r, err = str.Export("/other/", false)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"name": "keith"}, r)
+
+	r, err = str.Export("/app", false)
+	assert.NoError(t, err)
+	assert.NotContains(t, r, "debug")
+	total, err = str.DeletePath("/app")
+	assert.NoError(t, err)
+	assert.Equal(t, 4, total)
+
+	r, err = str.Export("/app", false)
+	assert.NoError(t, err)
+	assert.Nil(t, r)
+}
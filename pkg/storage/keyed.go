@@ -0,0 +1,143 @@
+package storage
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/b-b3rn4rd/json2ssm/pkg/source"
+)
+
+var _ Storage = &KeyedStorage{}
+
+// KeyedStorage names parameters with a key format before they are imported or
+// deleted, exported trees are parsed back with the inverse of the format.
+// Keys under root, a hierarchy such as the --path of put-json, are formatted
+// relative to it so that root stays a path, e.g. /app/db.host.
+type KeyedStorage struct {
+	store Storage
+	keys  *source.KeyFormat
+	root  string
+}
+
+func NewKeyed(store Storage, keys *source.KeyFormat, root string) *KeyedStorage {
+	return &KeyedStorage{
+		store: store,
+		keys:  keys,
+		root:  strings.Trim(root, "/"),
+	}
+}
+
+// format names values with the key format, keys under root are formatted
+// relative to it.
+func (s *KeyedStorage) format(values map[string]interface{}) (map[string]interface{}, error) {
+	if s.root == "" {
+		return s.keys.Format(values)
+	}
+
+	rel := map[string]interface{}{}
+	other := map[string]interface{}{}
+	named := map[string]interface{}{}
+
+	for k, v := range values {
+		switch {
+		case k == s.root:
+			named[k] = v
+		case strings.HasPrefix(k, s.root+"/"):
+			rel[strings.TrimPrefix(k, s.root+"/")] = v
+		default:
+			other[k] = v
+		}
+	}
+
+	formatted, err := s.keys.Format(rel)
+	if err != nil {
+		return nil, err
+	}
+
+	for name, v := range formatted {
+		named[s.root+"/"+name] = v
+	}
+
+	if formatted, err = s.keys.Format(other); err != nil {
+		return nil, err
+	}
+
+	for name, v := range formatted {
+		named[name] = v
+	}
+
+	return named, nil
+}
+
+func (s *KeyedStorage) Import(values map[string]interface{}, msg string, encrypt bool) (int, error) {
+	named, err := s.format(values)
+	if err != nil {
+		return 0, err
+	}
+
+	return s.store.Import(named, msg, encrypt)
+}
+
+func (s *KeyedStorage) Export(path string, decrypt bool) (interface{}, error) {
+	tree, err := s.store.Export(path, decrypt)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.Unformat(tree)
+}
+
+func (s *KeyedStorage) Delete(values map[string]interface{}) (int, error) {
+	named, err := s.format(values)
+	if err != nil {
+		return 0, err
+	}
+
+	return s.store.Delete(named)
+}
+
+// DeletePath deletes every parameter under the path whatever its format.
+func (s *KeyedStorage) DeletePath(path string) (int, error) {
+	return s.store.DeletePath(path)
+}
+
+// Unformat rebuilds a tree exported without the key format, formatted names
+// such as db.host or hosts[0] are single levels of such a tree.
+func (s *KeyedStorage) Unformat(tree interface{}) (interface{}, error) {
+	if tree == nil {
+		return nil, nil
+	}
+
+	names := map[string]interface{}{}
+	flattenTree(names, "", tree)
+
+	values, err := s.keys.Parse(names)
+	if err != nil {
+		return nil, err
+	}
+
+	return unflattern(values)
+}
+
+func flattenTree(values map[string]interface{}, key string, v interface{}) {
+	join := func(name string) string {
+		if key == "" {
+			return name
+		}
+
+		return key + "/" + name
+	}
+
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, item := range v {
+			flattenTree(values, join(k), item)
+		}
+	case []interface{}:
+		for i, item := range v {
+			flattenTree(values, join(strconv.Itoa(i)), item)
+		}
+	default:
+		values[key] = v
+	}
+}
@@ -0,0 +1,116 @@
+package storage_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/b-b3rn4rd/json2ssm/pkg/fakessm"
+	"github.com/b-b3rn4rd/json2ssm/pkg/source"
+	"github.com/b-b3rn4rd/json2ssm/pkg/storage"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeyedStorage(t *testing.T) {
+	dir, err := ioutil.TempDir("", "storage")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	file := storage.NewFile(filepath.Join(dir, "params.json"))
+	str := storage.NewKeyed(file, &source.KeyFormat{Separator: ".", Case: source.CaseKebab, Prefix: "app/", Index: source.IndexBrackets}, "")
+
+	values := map[string]interface{}{
+		"dbHost":         "localhost",
+		"hosts/0":        "a",
+		"hosts/1":        "b",
+		"servers/0/port": float64(80),
+	}
+
+	total, err := str.Import(values, "", false)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, total)
+
+	r, err := file.Export("/app", false)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"db-host":         "localhost",
+		"hosts[0]":        "a",
+		"hosts[1]":        "b",
+		"servers[0].port": float64(80),
+	}, r)
+
+	r, err = str.Export("/app", false)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"dbHost":  "localhost",
+		"hosts":   []interface{}{"a", "b"},
+		"servers": []interface{}{map[string]interface{}{"port": float64(80)}},
+	}, r)
+
+	total, err = str.Delete(values)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, total)
+
+	r, err = str.Export("/app", false)
+	assert.NoError(t, err)
+	assert.Nil(t, r)
+}
+
+func TestKeyedStorageRoot(t *testing.T) {
+	dir, err := ioutil.TempDir("", "storage")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	file := storage.NewFile(filepath.Join(dir, "params.json"))
+	str := storage.NewKeyed(file, &source.KeyFormat{Separator: "."}, "/app")
+
+	f := &source.Filter{Select: "/services/payments", Path: "/app"}
+	values, err := f.Apply(map[string]interface{}{
+		"services/payments/db/host": "localhost",
+		"services/payments/db/port": float64(5432),
+	})
+	assert.NoError(t, err)
+
+	total, err := str.Import(values, "", false)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, total)
+
+	r, err := file.Export("/app", false)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"db.host": "localhost",
+		"db.port": float64(5432),
+	}, r)
+
+	r, err = str.Export("/app", false)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"db": map[string]interface{}{"host": "localhost", "port": float64(5432)},
+	}, r)
+}
+
+func TestKeyedStorageSSMIndexFormat(t *testing.T) {
+	tests := []struct {
+		index string
+		fails bool
+	}{
+		{source.IndexPlain, false},
+		{source.IndexBrackets, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.index, func(t *testing.T) {
+			logger, _ := test.NewNullLogger()
+			str := storage.NewKeyed(storage.New(fakessm.New(), logger), &source.KeyFormat{Separator: ".", Index: tt.index}, "")
+
+			_, err := str.Import(map[string]interface{}{"app/hosts/0": "a"}, "", false)
+			if tt.fails {
+				assert.Contains(t, err.Error(), "name can only contain")
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,155 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ssm"
+)
+
+// SSM limits, see https://docs.aws.amazon.com/systems-manager/latest/userguide/sysman-paramstore-su-create.html
+const (
+	maxNameLength     = 1011
+	maxHierarchyDepth = 15
+	maxStandardValue  = 4096
+	maxAdvancedValue  = 8192
+	maxStandardParams = 10000
+)
+
+var validName = regexp.MustCompile(`^[a-zA-Z0-9_.\-/]+$`)
+
+// Problem is a flattened key or value that SSM would reject.
+type Problem struct {
+	Name    string
+	Message string
+}
+
+func (p Problem) String() string {
+	return fmt.Sprintf("%s: %s", p.Name, p.Message)
+}
+
+// LintError is returned by Import when values break SSM limits, nothing is
+// written in this case.
+type LintError struct {
+	Problems []Problem
+}
+
+func (e *LintError) Error() string {
+	msgs := make([]string, len(e.Problems))
+	for i, p := range e.Problems {
+		msgs[i] = p.String()
+	}
+
+	return fmt.Sprintf("%d problems found: %s", len(e.Problems), strings.Join(msgs, "; "))
+}
+
+// Lint checks flattened keys, as passed to Import, and their values against
+// SSM naming, size and quota limits and returns all problems sorted by name.
+// Parameters already stored are counted against the quota by Quota.
+func Lint(values map[string]interface{}) []Problem {
+	var problems []Problem
+	add := func(name, format string, args ...interface{}) {
+		problems = append(problems, Problem{Name: name, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if len(values) > maxStandardParams {
+		add("/", "%d parameters exceed the quota of %d standard parameters", len(values), maxStandardParams)
+	}
+
+	for k, v := range values {
+		name := fmt.Sprintf("/%s", k)
+
+		if len(name) > maxNameLength {
+			add(name, "name is %d characters long, the limit is %d", len(name), maxNameLength)
+		}
+
+		if !validName.MatchString(name) {
+			add(name, "name can only contain a-z, A-Z, 0-9, _, ., - and /")
+		}
+
+		if strings.HasSuffix(name, "/") || strings.Contains(name, "//") {
+			add(name, "name has an empty hierarchy level")
+		}
+
+		if depth := strings.Count(name, "/"); depth > maxHierarchyDepth {
+			add(name, "name has %d hierarchy levels, the limit is %d", depth, maxHierarchyDepth)
+		}
+
+		root := strings.ToLower(k)
+		if strings.HasPrefix(root, "aws") || strings.HasPrefix(root, "ssm") {
+			add(name, "name can't be prefixed with aws or ssm")
+		}
+
+		value := stringValue(v)
+		switch size := len(value); {
+		case size == 0:
+			add(name, "value can't be empty")
+		case size > maxAdvancedValue:
+			add(name, "value is %d bytes, the limit is %d", size, maxAdvancedValue)
+		case size > maxStandardValue:
+			add(name, "value is %d bytes, the standard tier limit is %d, the advanced tier allows %d", size, maxStandardValue, maxAdvancedValue)
+		}
+	}
+
+	sort.SliceStable(problems, func(i, j int) bool {
+		return problems[i].Name < problems[j].Name
+	})
+
+	return problems
+}
+
+// storedNames returns the names of standard parameters stored in the account
+// and region, advanced parameters have a quota of their own.
+func (s *SSMStorage) storedNames(ctx context.Context) (map[string]bool, error) {
+	names := map[string]bool{}
+
+	s.logger.Debug("describe parameters to count them against the quota")
+
+	err := s.svc.DescribeParametersPagesWithContext(ctx, &ssm.DescribeParametersInput{
+		MaxResults: aws.Int64(50),
+	}, func(page *ssm.DescribeParametersOutput, lastPage bool) bool {
+		for _, m := range page.Parameters {
+			if aws.StringValue(m.Tier) != ssm.ParameterTierAdvanced {
+				names[aws.StringValue(m.Name)] = true
+			}
+		}
+
+		return !lastPage
+	})
+
+	return names, err
+}
+
+// quotaProblem reports stored and new parameters exceeding the quota.
+func quotaProblem(total int) []Problem {
+	return []Problem{{
+		Name:    "/",
+		Message: fmt.Sprintf("%d stored and new parameters exceed the quota of %d standard parameters", total, maxStandardParams),
+	}}
+}
+
+// Quota counts the parameters already stored and the new names among the
+// flattened keys against the quota of standard parameters.
+func (s *SSMStorage) Quota(ctx context.Context, values map[string]interface{}) ([]Problem, error) {
+	stored, err := s.storedNames(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	total := len(stored)
+	for k := range values {
+		if !stored[fmt.Sprintf("/%s", k)] {
+			total++
+		}
+	}
+
+	if total > maxStandardParams {
+		return quotaProblem(total), nil
+	}
+
+	return nil, nil
+}
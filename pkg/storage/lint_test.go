@@ -0,0 +1,137 @@
+package storage_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/b-b3rn4rd/json2ssm/pkg/fakessm"
+	"github.com/b-b3rn4rd/json2ssm/pkg/storage"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLint(t *testing.T) {
+	deep := strings.Repeat("a/", 15) + "b"
+
+	tests := []struct {
+		name     string
+		values   map[string]interface{}
+		expected []storage.Problem
+	}{
+		{
+			name:   "valid",
+			values: map[string]interface{}{"app/db/host": "localhost", "app/db/port": float64(5432), "app/manager": nil},
+		},
+		{
+			name:     "characters",
+			values:   map[string]interface{}{"app/db host": "localhost"},
+			expected: []storage.Problem{{Name: "/app/db host", Message: "name can only contain a-z, A-Z, 0-9, _, ., - and /"}},
+		},
+		{
+			name:     "empty level",
+			values:   map[string]interface{}{"app//host": "localhost"},
+			expected: []storage.Problem{{Name: "/app//host", Message: "name has an empty hierarchy level"}},
+		},
+		{
+			name:     "depth",
+			values:   map[string]interface{}{deep: "x"},
+			expected: []storage.Problem{{Name: "/" + deep, Message: "name has 16 hierarchy levels, the limit is 15"}},
+		},
+		{
+			name:     "length",
+			values:   map[string]interface{}{strings.Repeat("a", 1011): "x"},
+			expected: []storage.Problem{{Name: "/" + strings.Repeat("a", 1011), Message: "name is 1012 characters long, the limit is 1011"}},
+		},
+		{
+			name:   "reserved prefixes",
+			values: map[string]interface{}{"aws/key": "x", "SSM/key": "x"},
+			expected: []storage.Problem{
+				{Name: "/SSM/key", Message: "name can't be prefixed with aws or ssm"},
+				{Name: "/aws/key", Message: "name can't be prefixed with aws or ssm"},
+			},
+		},
+		{
+			name:   "values",
+			values: map[string]interface{}{"app/a": "", "app/b": strings.Repeat("x", 4097), "app/c": strings.Repeat("x", 8193)},
+			expected: []storage.Problem{
+				{Name: "/app/a", Message: "value can't be empty"},
+				{Name: "/app/b", Message: "value is 4097 bytes, the standard tier limit is 4096, the advanced tier allows 8192"},
+				{Name: "/app/c", Message: "value is 8193 bytes, the limit is 8192"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, storage.Lint(tt.values))
+		})
+	}
+}
+
+func TestLintQuota(t *testing.T) {
+	values := map[string]interface{}{}
+	for i := 0; i <= 10000; i++ {
+		values[fmt.Sprintf("app/%d", i)] = "x"
+	}
+
+	assert.Equal(t, []storage.Problem{
+		{Name: "/", Message: "10001 parameters exceed the quota of 10000 standard parameters"},
+	}, storage.Lint(values))
+}
+
+func TestQuota(t *testing.T) {
+	svc := fakessm.New()
+	for i := 0; i < 9999; i++ {
+		_, err := svc.PutParameter(&ssm.PutParameterInput{Name: aws.String(fmt.Sprintf("/app/%d", i)), Value: aws.String("x"), Type: aws.String(ssm.ParameterTypeString)})
+		assert.NoError(t, err)
+	}
+
+	logger, _ := test.NewNullLogger()
+	str := storage.New(svc, logger)
+
+	tests := []struct {
+		name     string
+		values   map[string]interface{}
+		expected []storage.Problem
+	}{
+		{
+			name:   "within quota",
+			values: map[string]interface{}{"app/0": "y", "other/name": "x"},
+		},
+		{
+			name:   "stored and new exceed quota",
+			values: map[string]interface{}{"other/name": "x", "other/port": "80"},
+			expected: []storage.Problem{
+				{Name: "/", Message: "10001 stored and new parameters exceed the quota of 10000 standard parameters"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			problems, err := str.Quota(context.Background(), tt.values)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, problems)
+		})
+	}
+}
+
+func TestImportLints(t *testing.T) {
+	svc := fakessm.New()
+	logger, _ := test.NewNullLogger()
+	str := storage.New(svc, logger)
+
+	total, err := str.Import(map[string]interface{}{"app/name": "bernard", "app/empty": ""}, "", false)
+
+	assert.Equal(t, 0, total)
+	assert.Equal(t, &storage.LintError{Problems: []storage.Problem{
+		{Name: "/app/empty", Message: "value can't be empty"},
+	}}, err)
+
+	_, err = svc.GetParameter(&ssm.GetParameterInput{Name: aws.String("/app/name")})
+	assert.Error(t, err)
+}
@@ -0,0 +1,156 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/b-b3rn4rd/json2ssm/pkg/source"
+)
+
+// Metadata describes the exported version of a parameter.
+type Metadata struct {
+	Type             string    `json:"type"`
+	Version          int64     `json:"version"`
+	LastModifiedDate time.Time `json:"lastModifiedDate"`
+	LastModifiedUser string    `json:"lastModifiedUser,omitempty"`
+	Description      string    `json:"description,omitempty"`
+	Tier             string    `json:"tier,omitempty"`
+	KeyID            string    `json:"keyId,omitempty"`
+	Labels           []string  `json:"labels,omitempty"`
+}
+
+// ValueWithMetadata is a parameter value along with its metadata.
+type ValueWithMetadata struct {
+	Value interface{} `json:"value"`
+	Metadata
+}
+
+// ExportMetadata exports the path as ExportContext does, every leaf of the
+// tree is a *ValueWithMetadata, or a *Metadata when withValues is false.
+// Metadata describes the version that has been exported, e.g. the labeled one
+// with a Label filter, the rest of it is read with GetParameterHistory, one
+// call per parameter. Without values only DescribeParameters is called, the
+// metadata of the latest versions has no labels and the Label filter isn't
+// supported.
+func (s *SSMStorage) ExportMetadata(ctx context.Context, path string, opts ExportOptions, withValues bool) (interface{}, error) {
+	if !withValues {
+		return s.describeMetadata(ctx, path, opts)
+	}
+
+	params := map[string]*ssm.Parameter{}
+	values, _, err := s.flatten(ctx, path, opts, params)
+	if err != nil {
+		return nil, err
+	}
+
+	names := map[string]string{}
+	for name := range params {
+		names[strings.TrimPrefix(strings.TrimPrefix(name, path), "/")] = name
+	}
+
+	leaves := map[string]interface{}{}
+	i := 0
+
+	for k, v := range values {
+		if i%20 == 0 && i > 0 {
+			if err := s.pause(ctx); err != nil {
+				return nil, err
+			}
+		}
+		i++
+
+		p := params[names[k]]
+		m := &Metadata{
+			Type:             aws.StringValue(p.Type),
+			Version:          aws.Int64Value(p.Version),
+			LastModifiedDate: aws.TimeValue(p.LastModifiedDate),
+		}
+
+		s.logger.WithField("name", names[k]).Debug("getting parameter history")
+		err := s.svc.GetParameterHistoryPagesWithContext(ctx, &ssm.GetParameterHistoryInput{
+			Name: p.Name,
+		}, func(page *ssm.GetParameterHistoryOutput, lastPage bool) bool {
+			for _, h := range page.Parameters {
+				if aws.Int64Value(h.Version) == m.Version {
+					m.LastModifiedUser = aws.StringValue(h.LastModifiedUser)
+					m.Description = aws.StringValue(h.Description)
+					m.Tier = aws.StringValue(h.Tier)
+					m.KeyID = aws.StringValue(h.KeyId)
+					m.Labels = aws.StringValueSlice(h.Labels)
+				}
+			}
+
+			return !lastPage
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		leaves[k] = &ValueWithMetadata{Value: v, Metadata: *m}
+	}
+
+	return unflattern(leaves)
+}
+
+func (s *SSMStorage) describeMetadata(ctx context.Context, path string, opts ExportOptions) (interface{}, error) {
+	for _, patterns := range [][]string{opts.Include, opts.Exclude} {
+		if err := source.CheckPatterns(patterns); err != nil {
+			return nil, err
+		}
+	}
+
+	option := "Recursive"
+	if opts.NonRecursive {
+		option = "OneLevel"
+	}
+
+	filters := []*ssm.ParameterStringFilter{{
+		Key:    aws.String("Path"),
+		Option: aws.String(option),
+		Values: []*string{aws.String(path)},
+	}}
+
+	for _, f := range opts.Filters {
+		if aws.StringValue(f.Key) == "Label" {
+			return nil, fmt.Errorf("the Label filter needs values to be exported, it can't be used with metadata only")
+		}
+
+		filters = append(filters, f)
+	}
+
+	leaves := map[string]interface{}{}
+
+	s.logger.WithField("path", path).Debug("describe parameters by path")
+
+	err := s.svc.DescribeParametersPagesWithContext(ctx, &ssm.DescribeParametersInput{
+		ParameterFilters: filters,
+	}, func(page *ssm.DescribeParametersOutput, lastPage bool) bool {
+		for _, m := range page.Parameters {
+			name := aws.StringValue(m.Name)
+			if !opts.keep(path, name) {
+				continue
+			}
+
+			leaves[strings.TrimPrefix(strings.TrimPrefix(name, path), "/")] = &Metadata{
+				Type:             aws.StringValue(m.Type),
+				Version:          aws.Int64Value(m.Version),
+				LastModifiedDate: aws.TimeValue(m.LastModifiedDate),
+				LastModifiedUser: aws.StringValue(m.LastModifiedUser),
+				Description:      aws.StringValue(m.Description),
+				Tier:             aws.StringValue(m.Tier),
+				KeyID:            aws.StringValue(m.KeyId),
+			}
+		}
+
+		return !lastPage
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return unflattern(leaves)
+}
@@ -0,0 +1,96 @@
+package storage_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/b-b3rn4rd/json2ssm/pkg/fakessm"
+	"github.com/b-b3rn4rd/json2ssm/pkg/storage"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExportMetadata(t *testing.T) {
+	svc := fakessm.New()
+	logger, _ := test.NewNullLogger()
+	str := storage.New(svc, logger)
+
+	_, err := str.Import(map[string]interface{}{"app/port": float64(8080)}, "http port", false)
+	assert.NoError(t, err)
+
+	_, err = str.Import(map[string]interface{}{"app/port": float64(8081), "app/db/password": "secret"}, "", true)
+	assert.NoError(t, err)
+
+	_, err = svc.LabelParameterVersion(&ssm.LabelParameterVersionInput{
+		Name:   aws.String("/app/port"),
+		Labels: aws.StringSlice([]string{"prod"}),
+	})
+	assert.NoError(t, err)
+
+	tree, err := str.ExportMetadata(context.Background(), "/app", storage.ExportOptions{Decrypt: true}, true)
+	assert.NoError(t, err)
+
+	port := tree.(map[string]interface{})["port"].(*storage.ValueWithMetadata)
+	assert.Equal(t, float64(8081), port.Value)
+	assert.Equal(t, ssm.ParameterTypeSecureString, port.Type)
+	assert.Equal(t, int64(2), port.Version)
+	assert.Equal(t, []string{"prod"}, port.Labels)
+	assert.Equal(t, fakessm.DefaultKeyID, port.KeyID)
+	assert.Equal(t, "arn:aws:iam::123456789012:user/fakessm", port.LastModifiedUser)
+	assert.False(t, port.LastModifiedDate.IsZero())
+
+	password := tree.(map[string]interface{})["db"].(map[string]interface{})["password"].(*storage.ValueWithMetadata)
+	assert.Equal(t, "secret", password.Value)
+	assert.Equal(t, int64(1), password.Version)
+	assert.Empty(t, password.Labels)
+
+	tree, err = str.ExportMetadata(context.Background(), "/app", storage.ExportOptions{NonRecursive: true}, false)
+	assert.NoError(t, err)
+	assert.Len(t, tree, 1)
+
+	// metadata only is described without values or history, so labels are
+	// left out
+	meta := tree.(map[string]interface{})["port"].(*storage.Metadata)
+	assert.Equal(t, int64(2), meta.Version)
+	assert.Equal(t, ssm.ParameterTypeSecureString, meta.Type)
+	assert.Equal(t, fakessm.DefaultKeyID, meta.KeyID)
+	assert.Empty(t, meta.Labels)
+
+	_, err = str.ExportMetadata(context.Background(), "/app", storage.ExportOptions{
+		Filters: []*ssm.ParameterStringFilter{{Key: aws.String("Label"), Values: aws.StringSlice([]string{"prod"})}},
+	}, false)
+	assert.Error(t, err)
+}
+
+func TestExportMetadataLabel(t *testing.T) {
+	svc := fakessm.New()
+	logger, _ := test.NewNullLogger()
+	str := storage.New(svc, logger)
+
+	_, err := str.Import(map[string]interface{}{"app/name": "v1"}, "", false)
+	assert.NoError(t, err)
+
+	_, err = svc.LabelParameterVersion(&ssm.LabelParameterVersionInput{
+		Name:   aws.String("/app/name"),
+		Labels: aws.StringSlice([]string{"prod"}),
+	})
+	assert.NoError(t, err)
+
+	_, err = str.Import(map[string]interface{}{"app/name": "v2"}, "", false)
+	assert.NoError(t, err)
+
+	tree, err := str.ExportMetadata(context.Background(), "/app", storage.ExportOptions{
+		Filters: []*ssm.ParameterStringFilter{{
+			Key:    aws.String("Label"),
+			Values: aws.StringSlice([]string{"prod"}),
+		}},
+	}, true)
+	assert.NoError(t, err)
+
+	name := tree.(map[string]interface{})["name"].(*storage.ValueWithMetadata)
+	assert.Equal(t, "v1", name.Value)
+	assert.Equal(t, int64(1), name.Version)
+	assert.Equal(t, []string{"prod"}, name.Labels)
+}
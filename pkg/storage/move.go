@@ -0,0 +1,140 @@
+package storage
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ssm"
+)
+
+// Move copies every parameter under from to the same relative name under to,
+// verifies the copies and only then deletes the originals. Copies overwrite,
+// so a failed move can be resumed by running it again.
+func (s *SSMStorage) Move(from, to string) (int, error) {
+	return s.MoveTo(s, from, to, false)
+}
+
+// MoveTo works as Move but writes copies into the dst storage, which may use
+// another account or region. Paths may only be equal or nested when distinct
+// confirms dst is in another account or region, otherwise the copies would
+// overwrite the originals and then be deleted with them.
+func (s *SSMStorage) MoveTo(dst *SSMStorage, from, to string, distinct bool) (int, error) {
+	from = strings.TrimSuffix(from, "/")
+	to = strings.TrimSuffix(to, "/")
+
+	if !strings.HasPrefix(from, "/") || !strings.HasPrefix(to, "/") {
+		return 0, fmt.Errorf("paths must be absolute, got %q and %q", from, to)
+	}
+
+	if !distinct && (strings.HasPrefix(to+"/", from+"/") || strings.HasPrefix(from+"/", to+"/")) {
+		return 0, fmt.Errorf("paths %s and %s overlap", from, to)
+	}
+
+	params, err := s.Snapshot(from)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(params) == 0 {
+		return 0, fmt.Errorf("no parameters found under %s", from)
+	}
+
+	rename := func(name string) string {
+		return to + strings.TrimPrefix(name, from)
+	}
+
+	if failed := dst.putParameters(params, rename); len(failed) > 0 {
+		return 0, fmt.Errorf("copy failed for %d parameter(s) (%s), originals under %s are untouched, re-run move to resume",
+			len(failed), strings.Join(failed, ", "), from)
+	}
+
+	if mismatched, err := dst.verifyCopies(params, rename); err != nil {
+		return 0, err
+	} else if len(mismatched) > 0 {
+		return 0, fmt.Errorf("copies of %d parameter(s) do not match (%s), originals under %s are untouched, re-run move to resume",
+			len(mismatched), strings.Join(mismatched, ", "), from)
+	}
+
+	names := make([]string, 0, len(params))
+	for _, p := range params {
+		names = append(names, p.Name)
+	}
+
+	deleted, err := s.deleteNames(names)
+	if err != nil {
+		return deleted, fmt.Errorf("%d of %d originals deleted, copies under %s are complete, re-run move to finish: %s",
+			deleted, len(names), to, err)
+	}
+
+	return deleted, nil
+}
+
+func (s *SSMStorage) verifyCopies(params []*Parameter, rename func(string) string) ([]string, error) {
+	expected := map[string]*Parameter{}
+	names := make([]*string, 0, len(params))
+
+	for _, p := range params {
+		name := rename(p.Name)
+		expected[name] = p
+		names = append(names, aws.String(name))
+	}
+
+	var mismatched []string
+
+	for start := 0; start < len(names); start += 10 {
+		end := start + 10
+		if end > len(names) {
+			end = len(names)
+		}
+
+		resp, err := s.svc.GetParameters(&ssm.GetParametersInput{
+			Names:          names[start:end],
+			WithDecryption: aws.Bool(true),
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, name := range resp.InvalidParameters {
+			mismatched = append(mismatched, expected[aws.StringValue(name)].Name)
+		}
+
+		for _, c := range resp.Parameters {
+			p := expected[aws.StringValue(c.Name)]
+			if aws.StringValue(c.Value) != p.Value || aws.StringValue(c.Type) != p.Type {
+				mismatched = append(mismatched, p.Name)
+			}
+		}
+	}
+
+	return mismatched, nil
+}
+
+func (s *SSMStorage) deleteNames(names []string) (int, error) {
+	var deleted int
+
+	for start := 0; start < len(names); start += 10 {
+		end := start + 10
+		if end > len(names) {
+			end = len(names)
+		}
+
+		s.logger.WithField("names", names[start:end]).Debug("deleting ssm parameters")
+
+		resp, err := s.svc.DeleteParameters(&ssm.DeleteParametersInput{
+			Names: aws.StringSlice(names[start:end]),
+		})
+		if err != nil {
+			return deleted, err
+		}
+
+		deleted += len(resp.DeletedParameters)
+
+		if len(resp.InvalidParameters) > 0 {
+			return deleted, fmt.Errorf("can't delete %s", strings.Join(aws.StringValueSlice(resp.InvalidParameters), ", "))
+		}
+	}
+
+	return deleted, nil
+}
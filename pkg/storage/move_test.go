@@ -0,0 +1,224 @@
+package storage_test
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/b-b3rn4rd/json2ssm/mocks"
+	"github.com/b-b3rn4rd/json2ssm/pkg/fakessm"
+	"github.com/b-b3rn4rd/json2ssm/pkg/storage"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestMove(t *testing.T) {
+	s := &mocks.SSMAPI{}
+
+	s.On("DescribeParametersPages", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		cb := args.Get(1).(func(*ssm.DescribeParametersOutput, bool) bool)
+		cb(&ssm.DescribeParametersOutput{Parameters: []*ssm.ParameterMetadata{
+			{
+				Name:        aws.String("/app/db/host"),
+				Type:        aws.String(ssm.ParameterTypeString),
+				Description: aws.String("database host"),
+				Tier:        aws.String(ssm.ParameterTierStandard),
+			},
+			{
+				Name:  aws.String("/app/db/password"),
+				Type:  aws.String(ssm.ParameterTypeSecureString),
+				KeyId: aws.String("alias/aws/ssm"),
+				Tier:  aws.String(ssm.ParameterTierAdvanced),
+			},
+		}}, true)
+	}).Return(nil)
+
+	s.On("GetParametersByPathPages", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		cb := args.Get(1).(func(*ssm.GetParametersByPathOutput, bool) bool)
+		cb(&ssm.GetParametersByPathOutput{Parameters: []*ssm.Parameter{
+			{Name: aws.String("/app/db/host"), Type: aws.String(ssm.ParameterTypeString), Value: aws.String("localhost")},
+			{Name: aws.String("/app/db/password"), Type: aws.String(ssm.ParameterTypeSecureString), Value: aws.String("secret")},
+		}}, true)
+	}).Return(nil)
+
+	s.On("ListTagsForResource", mock.Anything).Return(&ssm.ListTagsForResourceOutput{TagList: []*ssm.Tag{
+		{Key: aws.String("type"), Value: aws.String("string")},
+	}}, nil)
+
+	putParameterExpectedInput := map[string]*ssm.PutParameterInput{
+		"/app/database/host": {
+			Name:        aws.String("/app/database/host"),
+			Value:       aws.String("localhost"),
+			Type:        aws.String(ssm.ParameterTypeString),
+			Overwrite:   aws.Bool(true),
+			Description: aws.String("database host"),
+			Tier:        aws.String(ssm.ParameterTierStandard),
+		},
+		"/app/database/password": {
+			Name:      aws.String("/app/database/password"),
+			Value:     aws.String("secret"),
+			Type:      aws.String(ssm.ParameterTypeSecureString),
+			Overwrite: aws.Bool(true),
+			KeyId:     aws.String("alias/aws/ssm"),
+			Tier:      aws.String(ssm.ParameterTierAdvanced),
+		},
+	}
+
+	s.On("PutParameter", mock.MatchedBy(func(input *ssm.PutParameterInput) bool {
+		return assert.Equal(t, putParameterExpectedInput[aws.StringValue(input.Name)], input)
+	})).Return(&ssm.PutParameterOutput{}, nil)
+
+	s.On("AddTagsToResource", mock.Anything).Return(&ssm.AddTagsToResourceOutput{}, nil)
+
+	s.On("GetParameters", mock.Anything).Return(&ssm.GetParametersOutput{Parameters: []*ssm.Parameter{
+		{Name: aws.String("/app/database/host"), Type: aws.String(ssm.ParameterTypeString), Value: aws.String("localhost")},
+		{Name: aws.String("/app/database/password"), Type: aws.String(ssm.ParameterTypeSecureString), Value: aws.String("secret")},
+	}}, nil)
+
+	s.On("DeleteParameters", &ssm.DeleteParametersInput{
+		Names: aws.StringSlice([]string{"/app/db/host", "/app/db/password"}),
+	}).Return(&ssm.DeleteParametersOutput{
+		DeletedParameters: aws.StringSlice([]string{"/app/db/host", "/app/db/password"}),
+	}, nil)
+
+	logger, _ := test.NewNullLogger()
+	str := storage.New(s, logger)
+	total, err := str.Move("/app/db", "/app/database/")
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, total)
+	s.AssertNumberOfCalls(t, "PutParameter", 2)
+	s.AssertNumberOfCalls(t, "AddTagsToResource", 2)
+	s.AssertNumberOfCalls(t, "DeleteParameters", 1)
+}
+
+func TestMoveKeepsOriginalsOnMismatch(t *testing.T) {
+	s := &mocks.SSMAPI{}
+
+	s.On("DescribeParametersPages", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		cb := args.Get(1).(func(*ssm.DescribeParametersOutput, bool) bool)
+		cb(&ssm.DescribeParametersOutput{Parameters: []*ssm.ParameterMetadata{
+			{Name: aws.String("/app/db/host"), Type: aws.String(ssm.ParameterTypeString)},
+		}}, true)
+	}).Return(nil)
+
+	s.On("GetParametersByPathPages", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		cb := args.Get(1).(func(*ssm.GetParametersByPathOutput, bool) bool)
+		cb(&ssm.GetParametersByPathOutput{Parameters: []*ssm.Parameter{
+			{Name: aws.String("/app/db/host"), Type: aws.String(ssm.ParameterTypeString), Value: aws.String("localhost")},
+		}}, true)
+	}).Return(nil)
+
+	s.On("ListTagsForResource", mock.Anything).Return(&ssm.ListTagsForResourceOutput{}, nil)
+	s.On("PutParameter", mock.Anything).Return(&ssm.PutParameterOutput{}, nil)
+	s.On("GetParameters", mock.Anything).Return(&ssm.GetParametersOutput{
+		InvalidParameters: aws.StringSlice([]string{"/app/database/host"}),
+	}, nil)
+
+	logger, _ := test.NewNullLogger()
+	str := storage.New(s, logger)
+	_, err := str.Move("/app/db", "/app/database")
+
+	assert.Error(t, err)
+	s.AssertNotCalled(t, "DeleteParameters", mock.Anything)
+}
+
+func TestMoveOverlappingPaths(t *testing.T) {
+	logger, _ := test.NewNullLogger()
+	str := storage.New(&mocks.SSMAPI{}, logger)
+
+	_, err := str.Move("/app", "/app/db")
+	assert.Error(t, err)
+}
+
+func TestMoveRoundTrip(t *testing.T) {
+	s := fakessm.New()
+	s.PutParameter(&ssm.PutParameterInput{
+		Name:        aws.String("/app/db/host"),
+		Value:       aws.String("localhost"),
+		Type:        aws.String(ssm.ParameterTypeString),
+		Description: aws.String("database host"),
+	})
+	s.PutParameter(&ssm.PutParameterInput{
+		Name:  aws.String("/app/db/password"),
+		Value: aws.String("secret"),
+		Type:  aws.String(ssm.ParameterTypeSecureString),
+		Tier:  aws.String(ssm.ParameterTierAdvanced),
+	})
+	s.AddTagsToResource(&ssm.AddTagsToResourceInput{
+		ResourceId:   aws.String("/app/db/host"),
+		ResourceType: aws.String(ssm.ResourceTypeForTaggingParameter),
+		Tags:         []*ssm.Tag{{Key: aws.String("type"), Value: aws.String("string")}},
+	})
+
+	logger, _ := test.NewNullLogger()
+	str := storage.New(s, logger)
+	total, err := str.Move("/app/db", "/app/database")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, total)
+
+	params, err := str.Snapshot("/app")
+	assert.NoError(t, err)
+	assert.Equal(t, []*storage.Parameter{
+		{
+			Name:        "/app/database/host",
+			Value:       "localhost",
+			Type:        ssm.ParameterTypeString,
+			Description: "database host",
+			Tier:        ssm.ParameterTierStandard,
+			Tags:        map[string]string{"type": "string"},
+		},
+		{
+			Name:  "/app/database/password",
+			Value: "secret",
+			Type:  ssm.ParameterTypeSecureString,
+			Tier:  ssm.ParameterTierAdvanced,
+			KeyID: fakessm.DefaultKeyID,
+		},
+	}, params)
+}
+
+func TestMoveToAnotherStorage(t *testing.T) {
+	src := fakessm.New()
+	src.PutParameter(&ssm.PutParameterInput{
+		Name:  aws.String("/app/db/host"),
+		Value: aws.String("localhost"),
+		Type:  aws.String(ssm.ParameterTypeString),
+	})
+	dst := fakessm.New()
+
+	logger, _ := test.NewNullLogger()
+	str := storage.New(src, logger)
+	total, err := str.MoveTo(storage.New(dst, logger), "/app", "/app", true)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, total)
+
+	_, err = src.GetParameter(&ssm.GetParameterInput{Name: aws.String("/app/db/host")})
+	assert.Error(t, err)
+
+	out, err := dst.GetParameter(&ssm.GetParameterInput{Name: aws.String("/app/db/host")})
+	assert.NoError(t, err)
+	assert.Equal(t, "localhost", aws.StringValue(out.Parameter.Value))
+}
+
+func TestMoveToSameAccount(t *testing.T) {
+	svc := fakessm.New()
+	svc.PutParameter(&ssm.PutParameterInput{
+		Name:  aws.String("/app/db/host"),
+		Value: aws.String("localhost"),
+		Type:  aws.String(ssm.ParameterTypeString),
+	})
+
+	logger, _ := test.NewNullLogger()
+	str := storage.New(svc, logger)
+
+	for _, to := range []string{"/app", "/app/db", "/app/"} {
+		_, err := str.MoveTo(storage.New(svc, logger), "/app", to, false)
+		assert.Error(t, err, to)
+	}
+
+	out, err := svc.GetParameter(&ssm.GetParameterInput{Name: aws.String("/app/db/host")})
+	assert.NoError(t, err)
+	assert.Equal(t, "localhost", aws.StringValue(out.Parameter.Value))
+}
@@ -0,0 +1,229 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ssm"
+)
+
+const (
+	// getParametersLimit is the maximum number of names GetParameters accepts.
+	getParametersLimit = 10
+	// defaultRefDepth is the MaxDepth used when it is zero.
+	defaultRefDepth = 5
+)
+
+var refPattern = regexp.MustCompile(`\{\{\s*ssm:([^}\s]+)\s*\}\}`)
+
+// ResolveOptions configures ResolveRefs.
+type ResolveOptions struct {
+	// Decrypt returns referenced secure string values decrypted.
+	Decrypt bool
+	// MaxDepth limits how many references can be followed in a chain, zero
+	// means defaultRefDepth.
+	MaxDepth int
+}
+
+type resolver struct {
+	s      *SSMStorage
+	ctx    context.Context
+	opts   ResolveOptions
+	values map[string]interface{}
+}
+
+// ResolveRefs replaces {{ssm:/name}} references in string values of an
+// exported tree. A value that consists of a single reference is replaced with
+// the typed value of the referenced parameter, references inside longer
+// strings are replaced with the value as a string. Referenced values may hold
+// references themselves, chains longer than MaxDepth and cycles are errors.
+func (s *SSMStorage) ResolveRefs(ctx context.Context, tree interface{}, opts ResolveOptions) (interface{}, error) {
+	if opts.MaxDepth == 0 {
+		opts.MaxDepth = defaultRefDepth
+	}
+
+	r := &resolver{s: s, ctx: ctx, opts: opts, values: map[string]interface{}{}}
+
+	// prefetch referenced parameters level by level, so that every level is
+	// fetched with as few GetParameters calls as possible
+	pending := collectRefs(tree, nil)
+	for depth := 0; len(pending) > 0 && depth <= opts.MaxDepth; depth++ {
+		fetched, err := r.fetch(pending)
+		if err != nil {
+			return nil, err
+		}
+
+		pending = nil
+		for _, v := range fetched {
+			pending = collectRefs(v, pending)
+		}
+	}
+
+	var errs []string
+	result := r.walk(tree, "", &errs)
+	if len(errs) > 0 {
+		sort.Strings(errs)
+		return nil, fmt.Errorf("can't resolve references: %s", strings.Join(errs, "; "))
+	}
+
+	return result, nil
+}
+
+func collectRefs(v interface{}, names []string) []string {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for _, item := range v {
+			names = collectRefs(item, names)
+		}
+	case []interface{}:
+		for _, item := range v {
+			names = collectRefs(item, names)
+		}
+	case string:
+		for _, m := range refPattern.FindAllStringSubmatch(v, -1) {
+			names = append(names, m[1])
+		}
+	}
+
+	return names
+}
+
+// fetch gets typed values of parameters that haven't been fetched yet and
+// returns them.
+func (r *resolver) fetch(names []string) ([]interface{}, error) {
+	var missing []string
+	seen := map[string]bool{}
+	for _, name := range names {
+		if _, ok := r.values[name]; !ok && !seen[name] {
+			seen[name] = true
+			missing = append(missing, name)
+		}
+	}
+
+	var fetched []interface{}
+	for i := 0; i < len(missing); i += getParametersLimit {
+		end := i + getParametersLimit
+		if end > len(missing) {
+			end = len(missing)
+		}
+
+		r.s.logger.WithField("names", missing[i:end]).Debug("getting referenced parameters")
+
+		resp, err := r.s.svc.GetParametersWithContext(r.ctx, &ssm.GetParametersInput{
+			Names:          aws.StringSlice(missing[i:end]),
+			WithDecryption: aws.Bool(r.opts.Decrypt),
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		if len(resp.InvalidParameters) > 0 {
+			return nil, fmt.Errorf("referenced parameters don't exist: %s", strings.Join(aws.StringValueSlice(resp.InvalidParameters), ", "))
+		}
+
+		for _, p := range resp.Parameters {
+			name := aws.StringValue(p.Name)
+
+			tags, err := r.s.svc.ListTagsForResourceWithContext(r.ctx, &ssm.ListTagsForResourceInput{
+				ResourceType: aws.String(ssm.ResourceTypeForTaggingParameter),
+				ResourceId:   aws.String(name),
+			})
+			if err != nil {
+				return nil, err
+			}
+
+			// values are kept under the name as referenced, e.g. /name:3 is
+			// returned as /name with the selector :3
+			ref := name + aws.StringValue(p.Selector)
+			r.values[ref] = typedValue(typeTag(tags.TagList), aws.StringValue(p.Value))
+			fetched = append(fetched, r.values[ref])
+		}
+	}
+
+	return fetched, nil
+}
+
+func (r *resolver) walk(v interface{}, key string, errs *[]string) interface{} {
+	join := func(k string) string {
+		return key + "/" + k
+	}
+
+	switch v := v.(type) {
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, item := range v {
+			m[k] = r.walk(item, join(k), errs)
+		}
+		return m
+	case []interface{}:
+		s := make([]interface{}, len(v))
+		for i, item := range v {
+			s[i] = r.walk(item, join(strconv.Itoa(i)), errs)
+		}
+		return s
+	case string:
+		resolved, err := r.expand(v, nil)
+		if err != nil {
+			*errs = append(*errs, fmt.Sprintf("%s: %s", key, err))
+			return v
+		}
+		return resolved
+	}
+
+	return v
+}
+
+// expand resolves references in a string, chain holds references followed to
+// get to it.
+func (r *resolver) expand(s string, chain []string) (interface{}, error) {
+	follow := func(name string) (interface{}, error) {
+		for _, c := range chain {
+			if c == name {
+				return nil, fmt.Errorf("reference cycle %s", strings.Join(append(chain, name), " -> "))
+			}
+		}
+
+		if len(chain) >= r.opts.MaxDepth {
+			return nil, fmt.Errorf("references %s exceed the maximum depth of %d", strings.Join(append(chain, name), " -> "), r.opts.MaxDepth)
+		}
+
+		v, ok := r.values[name]
+		if !ok {
+			return nil, fmt.Errorf("referenced parameter %s hasn't been fetched", name)
+		}
+
+		if str, ok := v.(string); ok {
+			return r.expand(str, append(chain[:len(chain):len(chain)], name))
+		}
+
+		return v, nil
+	}
+
+	if m := refPattern.FindStringSubmatch(s); m != nil && m[0] == s {
+		return follow(m[1])
+	}
+
+	var err error
+	result := refPattern.ReplaceAllStringFunc(s, func(ref string) string {
+		if err != nil {
+			return ref
+		}
+
+		var v interface{}
+		if v, err = follow(refPattern.FindStringSubmatch(ref)[1]); err != nil {
+			return ref
+		}
+
+		return stringValue(v)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
@@ -0,0 +1,110 @@
+package storage_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/b-b3rn4rd/json2ssm/pkg/fakessm"
+	"github.com/b-b3rn4rd/json2ssm/pkg/storage"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+)
+
+type countingSSM struct {
+	*fakessm.SSM
+	getParameters int
+}
+
+func (c *countingSSM) GetParametersWithContext(ctx aws.Context, input *ssm.GetParametersInput, opts ...request.Option) (*ssm.GetParametersOutput, error) {
+	c.getParameters++
+	return c.SSM.GetParametersWithContext(ctx, input, opts...)
+}
+
+func TestResolveRefs(t *testing.T) {
+	svc := &countingSSM{SSM: fakessm.New()}
+	logger, _ := test.NewNullLogger()
+	str := storage.New(svc, logger)
+
+	_, err := str.Import(map[string]interface{}{
+		"shared/db/host":   "db.internal",
+		"shared/db/port":   float64(5432),
+		"shared/db/alias":  "{{ssm:/shared/db/host}}",
+		"shared/loop/a":    "{{ssm:/shared/loop/b}}",
+		"shared/loop/b":    "{{ssm:/shared/loop/a}}",
+		"shared/chain/a":   "{{ssm:/shared/chain/b}}",
+		"shared/chain/b":   "{{ssm:/shared/chain/c}}",
+		"shared/chain/c":   "end",
+		"shared/db/secret": "password",
+	}, "", false)
+	assert.NoError(t, err)
+
+	tree := map[string]interface{}{
+		"host":  "{{ssm:/shared/db/host}}",
+		"port":  "{{ ssm:/shared/db/port }}",
+		"url":   "postgres://{{ssm:/shared/db/alias}}:{{ssm:/shared/db/port}}/app",
+		"hosts": []interface{}{"{{ssm:/shared/db/alias}}", "local"},
+		"debug": true,
+	}
+
+	r, err := str.ResolveRefs(context.Background(), tree, storage.ResolveOptions{MaxDepth: 5})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"host":  "db.internal",
+		"port":  float64(5432),
+		"url":   "postgres://db.internal:5432/app",
+		"hosts": []interface{}{"db.internal", "local"},
+		"debug": true,
+	}, r)
+	// the alias target is referenced by the tree as well, so it is fetched once
+	// with the first level
+	assert.Equal(t, 1, svc.getParameters)
+
+	_, err = str.Import(map[string]interface{}{"shared/db/host": "db2.internal"}, "", false)
+	assert.NoError(t, err)
+
+	// selected versions are matched by the reference and zero is the default
+	// depth
+	r, err = str.ResolveRefs(context.Background(), map[string]interface{}{
+		"old":   "{{ssm:/shared/db/host:1}}",
+		"new":   "{{ssm:/shared/db/host}}",
+		"chain": "{{ssm:/shared/chain/a}}",
+	}, storage.ResolveOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"old":   "db.internal",
+		"new":   "db2.internal",
+		"chain": "end",
+	}, r)
+
+	tests := map[string]struct {
+		tree     interface{}
+		maxDepth int
+		err      string
+	}{
+		"cycle": {
+			tree:     map[string]interface{}{"a": "{{ssm:/shared/loop/a}}"},
+			maxDepth: 5,
+			err:      "can't resolve references: /a: reference cycle /shared/loop/a -> /shared/loop/b -> /shared/loop/a",
+		},
+		"depth": {
+			tree:     map[string]interface{}{"a": "{{ssm:/shared/chain/a}}"},
+			maxDepth: 2,
+			err:      "can't resolve references: /a: references /shared/chain/a -> /shared/chain/b -> /shared/chain/c exceed the maximum depth of 2",
+		},
+		"missing": {
+			tree:     map[string]interface{}{"a": "{{ssm:/shared/missing}}"},
+			maxDepth: 5,
+			err:      "referenced parameters don't exist: /shared/missing",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := str.ResolveRefs(context.Background(), tt.tree, storage.ResolveOptions{MaxDepth: tt.maxDepth})
+			assert.EqualError(t, err, tt.err)
+		})
+	}
+}
@@ -0,0 +1,210 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+	"github.com/aws/aws-sdk-go/service/secretsmanager/secretsmanageriface"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/cheggaaa/pb.v1"
+)
+
+var _ Storage = &SecretsManagerStorage{}
+
+// SecretsManagerStorage stores every flattened key as a separate secret named
+// after the key, value types are kept in the type tag as with SSM.
+type SecretsManagerStorage struct {
+	svc    secretsmanageriface.SecretsManagerAPI
+	logger *logrus.Logger
+}
+
+func NewSecretsManager(svc secretsmanageriface.SecretsManagerAPI, logger *logrus.Logger) *SecretsManagerStorage {
+	return &SecretsManagerStorage{
+		svc:    svc,
+		logger: logger,
+	}
+}
+
+// list returns the types of secrets under the path keyed by secret name.
+func (s *SecretsManagerStorage) list(path string) (map[string]string, error) {
+	prefix := strings.TrimSuffix(path, "/") + "/"
+	types := map[string]string{}
+
+	s.logger.WithField("path", path).Debug("list secrets by path")
+
+	err := s.svc.ListSecretsPages(&secretsmanager.ListSecretsInput{}, func(page *secretsmanager.ListSecretsOutput, lastPage bool) bool {
+		for _, secret := range page.SecretList {
+			name := aws.StringValue(secret.Name)
+			if !strings.HasPrefix(name, prefix) || secret.DeletedDate != nil {
+				continue
+			}
+
+			types[name] = "string"
+			for _, tag := range secret.Tags {
+				if aws.StringValue(tag.Key) == "type" {
+					types[name] = aws.StringValue(tag.Value)
+				}
+			}
+		}
+
+		return !lastPage
+	})
+
+	return types, err
+}
+
+// Export ignores decrypt, secret values are always decrypted.
+func (s *SecretsManagerStorage) Export(path string, decrypt bool) (interface{}, error) {
+	prefix := strings.TrimSuffix(path, "/") + "/"
+
+	types, err := s.list(path)
+	if err != nil {
+		return nil, err
+	}
+
+	bar := pb.New(len(types))
+	bar.Output = os.Stderr
+	bar.Start()
+
+	values := map[string]interface{}{}
+	for name, vType := range types {
+		s.logger.WithField("name", name).Debug("getting secret value")
+
+		resp, err := s.svc.GetSecretValue(&secretsmanager.GetSecretValueInput{
+			SecretId: aws.String(name),
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		values[strings.TrimPrefix(name, prefix)] = typedValue(vType, aws.StringValue(resp.SecretString))
+		bar.Increment()
+	}
+
+	bar.Finish()
+
+	return unflattern(values)
+}
+
+// Import ignores encrypt, secrets are always encrypted.
+func (s *SecretsManagerStorage) Import(values map[string]interface{}, msg string, encrypt bool) (int, error) {
+	bar := pb.StartNew(len(values))
+	bar.Output = os.Stderr
+
+	for k, v := range values {
+		name := fmt.Sprintf("/%s", k)
+		tags := []*secretsmanager.Tag{{
+			Key:   aws.String("type"),
+			Value: aws.String(valueType(v)),
+		}}
+
+		s.logger.WithField("name", name).Debug("creating secret")
+
+		input := &secretsmanager.CreateSecretInput{
+			Name:         aws.String(name),
+			SecretString: aws.String(stringValue(v)),
+			Tags:         tags,
+		}
+		if msg != "" {
+			input.Description = aws.String(msg)
+		}
+
+		_, err := s.svc.CreateSecret(input)
+		if err == nil {
+			bar.Increment()
+			continue
+		}
+
+		if aerr, ok := err.(awserr.Error); !ok || aerr.Code() != secretsmanager.ErrCodeResourceExistsException {
+			return 0, err
+		}
+
+		s.logger.WithField("name", name).Debug("secret exists, putting secret value")
+
+		_, err = s.svc.PutSecretValue(&secretsmanager.PutSecretValueInput{
+			SecretId:     aws.String(name),
+			SecretString: aws.String(stringValue(v)),
+		})
+		if err != nil {
+			return 0, err
+		}
+
+		_, err = s.svc.TagResource(&secretsmanager.TagResourceInput{
+			SecretId: aws.String(name),
+			Tags:     tags,
+		})
+		if err != nil {
+			return 0, err
+		}
+
+		if msg != "" {
+			_, err = s.svc.UpdateSecret(&secretsmanager.UpdateSecretInput{
+				SecretId:    aws.String(name),
+				Description: aws.String(msg),
+			})
+			if err != nil {
+				return 0, err
+			}
+		}
+
+		bar.Increment()
+	}
+
+	bar.Finish()
+
+	return len(values), nil
+}
+
+// Delete removes secrets immediately without a recovery window, so that they
+// can be imported again straight away as with SSM.
+func (s *SecretsManagerStorage) Delete(values map[string]interface{}) (int, error) {
+	bar := pb.StartNew(len(values))
+	bar.Output = os.Stderr
+
+	for k := range values {
+		name := fmt.Sprintf("/%s", k)
+		s.logger.WithField("name", name).Debug("deleting secret")
+
+		_, err := s.svc.DeleteSecret(&secretsmanager.DeleteSecretInput{
+			SecretId:                   aws.String(name),
+			ForceDeleteWithoutRecovery: aws.Bool(true),
+		})
+		if err != nil {
+			return 0, err
+		}
+
+		bar.Increment()
+	}
+
+	bar.Finish()
+
+	return len(values), nil
+}
+
+func (s *SecretsManagerStorage) DeletePath(path string) (int, error) {
+	types, err := s.list(path)
+	if err != nil {
+		return 0, err
+	}
+
+	total := 0
+	for name := range types {
+		s.logger.WithField("name", name).Debug("deleting secret")
+
+		_, err := s.svc.DeleteSecret(&secretsmanager.DeleteSecretInput{
+			SecretId:                   aws.String(name),
+			ForceDeleteWithoutRecovery: aws.Bool(true),
+		})
+		if err != nil {
+			return total, err
+		}
+
+		total++
+	}
+
+	return total, nil
+}
@@ -0,0 +1,94 @@
+package storage_test
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+	"github.com/aws/aws-sdk-go/service/secretsmanager/secretsmanageriface"
+	"github.com/b-b3rn4rd/json2ssm/pkg/storage"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+)
+
+type SecretsManagerMock struct {
+	secretsmanageriface.SecretsManagerAPI
+	secrets map[string]*secretsmanager.SecretListEntry
+	values  map[string]string
+	deleted []string
+}
+
+func (s *SecretsManagerMock) ListSecretsPages(input *secretsmanager.ListSecretsInput, cb func(*secretsmanager.ListSecretsOutput, bool) bool) error {
+	out := &secretsmanager.ListSecretsOutput{}
+	for _, secret := range s.secrets {
+		out.SecretList = append(out.SecretList, secret)
+	}
+	cb(out, true)
+	return nil
+}
+
+func (s *SecretsManagerMock) GetSecretValue(input *secretsmanager.GetSecretValueInput) (*secretsmanager.GetSecretValueOutput, error) {
+	return &secretsmanager.GetSecretValueOutput{SecretString: aws.String(s.values[aws.StringValue(input.SecretId)])}, nil
+}
+
+func (s *SecretsManagerMock) CreateSecret(input *secretsmanager.CreateSecretInput) (*secretsmanager.CreateSecretOutput, error) {
+	name := aws.StringValue(input.Name)
+	if _, ok := s.secrets[name]; ok {
+		return nil, awserr.New(secretsmanager.ErrCodeResourceExistsException, "exists", nil)
+	}
+	s.secrets[name] = &secretsmanager.SecretListEntry{Name: input.Name, Tags: input.Tags}
+	s.values[name] = aws.StringValue(input.SecretString)
+	return &secretsmanager.CreateSecretOutput{}, nil
+}
+
+func (s *SecretsManagerMock) PutSecretValue(input *secretsmanager.PutSecretValueInput) (*secretsmanager.PutSecretValueOutput, error) {
+	s.values[aws.StringValue(input.SecretId)] = aws.StringValue(input.SecretString)
+	return &secretsmanager.PutSecretValueOutput{}, nil
+}
+
+func (s *SecretsManagerMock) TagResource(input *secretsmanager.TagResourceInput) (*secretsmanager.TagResourceOutput, error) {
+	s.secrets[aws.StringValue(input.SecretId)].Tags = input.Tags
+	return &secretsmanager.TagResourceOutput{}, nil
+}
+
+func (s *SecretsManagerMock) DeleteSecret(input *secretsmanager.DeleteSecretInput) (*secretsmanager.DeleteSecretOutput, error) {
+	s.deleted = append(s.deleted, aws.StringValue(input.SecretId))
+	delete(s.secrets, aws.StringValue(input.SecretId))
+	return &secretsmanager.DeleteSecretOutput{}, nil
+}
+
+func TestSecretsManagerStorage(t *testing.T) {
+	s := &SecretsManagerMock{
+		secrets: map[string]*secretsmanager.SecretListEntry{
+			"/app/name": {Name: aws.String("/app/name")},
+		},
+		values: map[string]string{"/app/name": "keith"},
+	}
+
+	logger, _ := test.NewNullLogger()
+	str := storage.NewSecretsManager(s, logger)
+
+	total, err := str.Import(map[string]interface{}{
+		"app/name":  "bernard",
+		"app/port":  float64(8080),
+		"app/debug": true,
+		"app/empty": nil,
+	}, "", false)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, total)
+
+	r, err := str.Export("/app", false)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"name":  "bernard",
+		"port":  float64(8080),
+		"debug": true,
+		"empty": nil,
+	}, r)
+
+	total, err = str.Delete(map[string]interface{}{"app/debug": true})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, total)
+	assert.Equal(t, []string{"/app/debug"}, s.deleted)
+}
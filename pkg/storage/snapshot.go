@@ -0,0 +1,234 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"gopkg.in/cheggaaa/pb.v1"
+)
+
+// Parameter is an SSM parameter together with the metadata required to
+// recreate it faithfully.
+type Parameter struct {
+	Name           string            `json:"name"`
+	Value          string            `json:"value"`
+	Type           string            `json:"type"`
+	Description    string            `json:"description,omitempty"`
+	Tier           string            `json:"tier,omitempty"`
+	KeyID          string            `json:"keyId,omitempty"`
+	AllowedPattern string            `json:"allowedPattern,omitempty"`
+	Policies       []string          `json:"policies,omitempty"`
+	Tags           map[string]string `json:"tags,omitempty"`
+}
+
+// Snapshot returns decrypted parameters under the given path with their
+// metadata and tags.
+func (s *SSMStorage) Snapshot(path string) ([]*Parameter, error) {
+	params := map[string]*Parameter{}
+	var order []string
+
+	s.logger.WithField("path", path).Debug("describe parameters by path")
+
+	err := s.svc.DescribeParametersPages(&ssm.DescribeParametersInput{
+		ParameterFilters: []*ssm.ParameterStringFilter{{
+			Key:    aws.String("Path"),
+			Option: aws.String("Recursive"),
+			Values: []*string{aws.String(path)},
+		}},
+	}, func(page *ssm.DescribeParametersOutput, lastPage bool) bool {
+		for _, m := range page.Parameters {
+			p := &Parameter{
+				Name:           aws.StringValue(m.Name),
+				Type:           aws.StringValue(m.Type),
+				Description:    aws.StringValue(m.Description),
+				Tier:           aws.StringValue(m.Tier),
+				AllowedPattern: aws.StringValue(m.AllowedPattern),
+			}
+
+			if p.Type == ssm.ParameterTypeSecureString {
+				p.KeyID = aws.StringValue(m.KeyId)
+			}
+
+			for _, policy := range m.Policies {
+				p.Policies = append(p.Policies, aws.StringValue(policy.PolicyText))
+			}
+
+			params[p.Name] = p
+			order = append(order, p.Name)
+		}
+
+		return !lastPage
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.svc.GetParametersByPathPages(&ssm.GetParametersByPathInput{
+		Path:           aws.String(path),
+		Recursive:      aws.Bool(true),
+		WithDecryption: aws.Bool(true),
+	}, func(page *ssm.GetParametersByPathOutput, lastPage bool) bool {
+		for _, p := range page.Parameters {
+			if v, ok := params[aws.StringValue(p.Name)]; ok {
+				v.Value = aws.StringValue(p.Value)
+			}
+		}
+
+		return !lastPage
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*Parameter, 0, len(order))
+	for i, name := range order {
+		if i%20 == 0 && i > 0 {
+			s.logger.Debugf("sleep for a %d seconds", s.sleep)
+			time.Sleep(time.Duration(s.sleep) * time.Second)
+		}
+
+		s.logger.WithField("name", name).Debug("getting parameter tags")
+		resp, err := s.svc.ListTagsForResource(&ssm.ListTagsForResourceInput{
+			ResourceType: aws.String(ssm.ResourceTypeForTaggingParameter),
+			ResourceId:   aws.String(name),
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, tag := range resp.TagList {
+			if params[name].Tags == nil {
+				params[name].Tags = map[string]string{}
+			}
+			params[name].Tags[aws.StringValue(tag.Key)] = aws.StringValue(tag.Value)
+		}
+
+		result = append(result, params[name])
+	}
+
+	return result, nil
+}
+
+// Restore (over)writes the given parameters with their metadata and tags,
+// parameters under from are written under to when both are specified.
+func (s *SSMStorage) Restore(params []*Parameter, from, to string) (int, error) {
+	rename := func(name string) string {
+		if from == "" || to == "" {
+			return name
+		}
+
+		return strings.TrimSuffix(to, "/") + strings.TrimPrefix(name, strings.TrimSuffix(from, "/"))
+	}
+
+	if failed := s.putParameters(params, rename); len(failed) > 0 {
+		return len(params) - len(failed), fmt.Errorf("can't restore %d parameter(s): %s", len(failed), strings.Join(failed, ", "))
+	}
+
+	return len(params), nil
+}
+
+func (s *SSMStorage) putParameters(params []*Parameter, rename func(string) string) []string {
+	var wg sync.WaitGroup
+	var mx sync.Mutex
+	var failed []string
+
+	bar := pb.New(len(params))
+	bar.Output = os.Stderr
+	bar.Start()
+
+	for i, p := range params {
+		wg.Add(1)
+
+		if i%10 == 0 && i > 0 {
+			s.logger.Debugf("sleep for a %d seconds", s.sleep)
+			time.Sleep(time.Duration(s.sleep) * time.Second)
+		}
+
+		go func(p *Parameter) {
+			defer func() {
+				bar.Increment()
+				wg.Done()
+			}()
+
+			name := rename(p.Name)
+			s.logger.WithField("name", name).Debugf("putting ssm parameter from %s", p.Name)
+
+			err := s.putParameter(p, name)
+			if err != nil {
+				s.logger.WithField("name", name).WithError(err).Info("can't put parameter")
+				mx.Lock()
+				failed = append(failed, p.Name)
+				mx.Unlock()
+			}
+		}(p)
+	}
+
+	wg.Wait()
+	bar.Finish()
+
+	sort.Strings(failed)
+
+	return failed
+}
+
+func (s *SSMStorage) putParameter(p *Parameter, name string) error {
+	input := &ssm.PutParameterInput{
+		Name:      aws.String(name),
+		Value:     aws.String(p.Value),
+		Type:      aws.String(p.Type),
+		Overwrite: aws.Bool(true),
+	}
+
+	if p.Description != "" {
+		input.Description = aws.String(p.Description)
+	}
+
+	if p.Tier != "" {
+		input.Tier = aws.String(p.Tier)
+	}
+
+	if p.KeyID != "" {
+		input.KeyId = aws.String(p.KeyID)
+	}
+
+	if p.AllowedPattern != "" {
+		input.AllowedPattern = aws.String(p.AllowedPattern)
+	}
+
+	if len(p.Policies) > 0 {
+		input.Policies = aws.String("[" + strings.Join(p.Policies, ",") + "]")
+	}
+
+	if _, err := s.svc.PutParameter(input); err != nil {
+		return err
+	}
+
+	if len(p.Tags) == 0 {
+		return nil
+	}
+
+	keys := make([]string, 0, len(p.Tags))
+	for k := range p.Tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	tags := make([]*ssm.Tag, 0, len(keys))
+	for _, k := range keys {
+		tags = append(tags, &ssm.Tag{Key: aws.String(k), Value: aws.String(p.Tags[k])})
+	}
+
+	_, err := s.svc.AddTagsToResource(&ssm.AddTagsToResourceInput{
+		ResourceId:   aws.String(name),
+		ResourceType: aws.String(ssm.ResourceTypeForTaggingParameter),
+		Tags:         tags,
+	})
+
+	return err
+}
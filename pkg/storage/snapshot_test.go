@@ -0,0 +1,53 @@
+package storage_test
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/b-b3rn4rd/json2ssm/mocks"
+	"github.com/b-b3rn4rd/json2ssm/pkg/storage"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRestore(t *testing.T) {
+	params := []*storage.Parameter{
+		{
+			Name:     "/app/db/password",
+			Value:    "secret",
+			Type:     ssm.ParameterTypeSecureString,
+			KeyID:    "alias/app",
+			Tier:     ssm.ParameterTierAdvanced,
+			Policies: []string{`{"Type":"NoChangeNotification"}`, `{"Type":"ExpirationNotification"}`},
+			Tags:     map[string]string{"type": "string", "owner": "platform"},
+		},
+	}
+
+	s := &mocks.SSMAPI{}
+	s.On("PutParameter", &ssm.PutParameterInput{
+		Name:      aws.String("/other/db/password"),
+		Value:     aws.String("secret"),
+		Type:      aws.String(ssm.ParameterTypeSecureString),
+		Overwrite: aws.Bool(true),
+		KeyId:     aws.String("alias/app"),
+		Tier:      aws.String(ssm.ParameterTierAdvanced),
+		Policies:  aws.String(`[{"Type":"NoChangeNotification"},{"Type":"ExpirationNotification"}]`),
+	}).Return(&ssm.PutParameterOutput{}, nil)
+	s.On("AddTagsToResource", &ssm.AddTagsToResourceInput{
+		ResourceId:   aws.String("/other/db/password"),
+		ResourceType: aws.String(ssm.ResourceTypeForTaggingParameter),
+		Tags: []*ssm.Tag{
+			{Key: aws.String("owner"), Value: aws.String("platform")},
+			{Key: aws.String("type"), Value: aws.String("string")},
+		},
+	}).Return(&ssm.AddTagsToResourceOutput{}, nil)
+
+	logger, _ := test.NewNullLogger()
+	str := storage.New(s, logger)
+	total, err := str.Restore(params, "/app", "/other/")
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, total)
+	s.AssertExpectations(t)
+}
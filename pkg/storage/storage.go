@@ -1,7 +1,10 @@
 package storage
 
 import (
+	"context"
+
 	"sync"
+	"sync/atomic"
 
 	"fmt"
 
@@ -18,16 +21,20 @@ import (
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/ssm"
 	"github.com/aws/aws-sdk-go/service/ssm/ssmiface"
+	"github.com/b-b3rn4rd/json2ssm/pkg/source"
 	"github.com/sirupsen/logrus"
 	"gopkg.in/cheggaaa/pb.v1"
 )
 
 type Storage interface {
-	Import(map[string]interface{}) (int16, error)
-	Export(string) (interface{}, error)
-	Delete(map[string]interface{}) (int16, error)
+	Import(values map[string]interface{}, msg string, encrypt bool) (int, error)
+	Export(path string, decrypt bool) (interface{}, error)
+	Delete(values map[string]interface{}) (int, error)
+	DeletePath(path string) (int, error)
 }
 
+var _ Storage = &SSMStorage{}
+
 type SSMStorage struct {
 	svc    ssmiface.SSMAPI
 	logger *logrus.Logger
@@ -42,42 +49,199 @@ func New(svc ssmiface.SSMAPI, logger *logrus.Logger) *SSMStorage {
 	}
 }
 
+// Progress is called every time a parameter has been processed, total may grow
+// while pages of parameters are fetched. Calls are never made concurrently.
+type Progress func(done, total int)
+
+// ImportOptions configures ImportContext.
+type ImportOptions struct {
+	// Message is used as parameters description.
+	Message string
+	// Encrypt stores all values as secure strings.
+	Encrypt  bool
+	Progress Progress
+}
+
+// ExportOptions configures ExportContext and FlattenContext.
+type ExportOptions struct {
+	// Decrypt returns secure string values decrypted.
+	Decrypt bool
+	// NonRecursive returns only parameters directly under the path.
+	NonRecursive bool
+	// Include and Exclude are globs matched against keys relative to the
+	// path, a key is also matched when one of its parents matches. Keys
+	// matching any Exclude glob are dropped, when Include is set only keys
+	// matching one of its globs are kept.
+	Include []string
+	Exclude []string
+	// Filters are passed to GetParametersByPath, e.g. Type, KeyId, Label
+	// and tag:<key>.
+	Filters  []*ssm.ParameterStringFilter
+	Progress Progress
+}
+
+// keep reports whether a parameter under the path passes Include and Exclude.
+func (o ExportOptions) keep(path, name string) bool {
+	key := strings.TrimPrefix(strings.TrimPrefix(name, path), "/")
+	if len(o.Include) > 0 && !source.MatchKey(key, o.Include) {
+		return false
+	}
+
+	return !source.MatchKey(key, o.Exclude)
+}
+
+// DeleteOptions configures DeleteContext.
+type DeleteOptions struct {
+	Progress Progress
+}
+
+type tracker struct {
+	mx          sync.Mutex
+	done, total int
+	progress    Progress
+}
+
+func (t *tracker) add(total int) {
+	t.mx.Lock()
+	defer t.mx.Unlock()
+
+	t.total += total
+	if t.progress != nil {
+		t.progress(t.done, t.total)
+	}
+}
+
+func (t *tracker) increment() {
+	t.mx.Lock()
+	defer t.mx.Unlock()
+
+	t.done++
+	if t.progress != nil {
+		t.progress(t.done, t.total)
+	}
+}
+
+// progressBar draws progress on stderr, it is used by the methods without
+// context.
+func progressBar() (Progress, func()) {
+	bar := pb.New(0)
+	bar.Output = os.Stderr
+	bar.Start()
+
+	return func(done, total int) {
+		bar.SetTotal(total)
+		bar.Set(done)
+	}, bar.Finish
+}
+
+// pause sleeps between batches of requests to avoid throttling, it returns
+// early when the context is done.
+func (s *SSMStorage) pause(ctx context.Context) error {
+	s.logger.Debugf("sleep for a %d seconds", s.sleep)
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(time.Duration(s.sleep) * time.Second):
+		return nil
+	}
+}
+
 func (s *SSMStorage) Export(path string, decrypt bool) (interface{}, error) {
+	progress, finish := progressBar()
+	defer finish()
+
+	return s.ExportContext(context.Background(), path, ExportOptions{Decrypt: decrypt, Progress: progress})
+}
+
+func (s *SSMStorage) ExportContext(ctx context.Context, path string, opts ExportOptions) (interface{}, error) {
+	values, _, err := s.FlattenContext(ctx, path, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return unflattern(values)
+}
+
+// Flatten returns parameters under the given path keyed by their name relative
+// to the path, along with the set of keys stored as secure strings.
+func (s *SSMStorage) Flatten(path string, decrypt bool) (map[string]interface{}, map[string]bool, error) {
+	progress, finish := progressBar()
+	defer finish()
+
+	return s.FlattenContext(context.Background(), path, ExportOptions{Decrypt: decrypt, Progress: progress})
+}
+
+func (s *SSMStorage) FlattenContext(ctx context.Context, path string, opts ExportOptions) (map[string]interface{}, map[string]bool, error) {
+	return s.flatten(ctx, path, opts, nil)
+}
+
+// flatten is FlattenContext, the parameters read are kept in params by their
+// name when it isn't nil.
+func (s *SSMStorage) flatten(ctx context.Context, path string, opts ExportOptions, params map[string]*ssm.Parameter) (map[string]interface{}, map[string]bool, error) {
 	values := map[string]interface{}{}
+	secure := map[string]bool{}
 	mx := sync.Mutex{}
 	s.logger.WithField("path", path).Debug("get parameters by path")
 
 	var wg sync.WaitGroup
 	var i uint32
+	var pauseErr error
 
-	bar := pb.New(0)
-	bar.Output = os.Stderr
-	bar.Start()
+	t := &tracker{progress: opts.Progress}
 
-	err := s.svc.GetParametersByPathPages(&ssm.GetParametersByPathInput{
-		Path:           aws.String(path),
-		Recursive:      aws.Bool(true),
-		WithDecryption: aws.Bool(decrypt),
-	}, func(page *ssm.GetParametersByPathOutput, lastPage bool) bool {
-		bar.SetTotal(int(bar.Total) + len(page.Parameters))
+	for _, patterns := range [][]string{opts.Include, opts.Exclude} {
+		if err := source.CheckPatterns(patterns); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	keep := func(name string) bool {
+		return opts.keep(path, name)
+	}
 
+	err := s.svc.GetParametersByPathPagesWithContext(ctx, &ssm.GetParametersByPathInput{
+		Path:             aws.String(path),
+		Recursive:        aws.Bool(!opts.NonRecursive),
+		WithDecryption:   aws.Bool(opts.Decrypt),
+		ParameterFilters: opts.Filters,
+	}, func(page *ssm.GetParametersByPathOutput, lastPage bool) bool {
+		var kept []*ssm.Parameter
 		for _, p := range page.Parameters {
-			wg.Add(1)
+			if keep(aws.StringValue(p.Name)) {
+				kept = append(kept, p)
+			}
+		}
+
+		t.add(len(kept))
+
+		for _, p := range kept {
+			if params != nil {
+				params[aws.StringValue(p.Name)] = p
+			}
+
+			if aws.StringValue(p.Type) == ssm.ParameterTypeSecureString {
+				mx.Lock()
+				secure[aws.StringValue(p.Name)] = true
+				mx.Unlock()
+			}
 
 			if i%20 == 0 && i > 0 {
-				s.logger.Debugf("sleep for a %d seconds", s.sleep)
-				time.Sleep(time.Duration(s.sleep) * time.Second)
+				if pauseErr = s.pause(ctx); pauseErr != nil {
+					return false
+				}
 			}
 
 			i++
+			wg.Add(1)
 			go func(name string, value string) {
 				defer func() {
-					bar.Increment()
+					t.increment()
 					wg.Done()
 				}()
 
 				s.logger.WithField("name", name).Debug("getting parameter type")
-				resp, err := s.svc.ListTagsForResource(&ssm.ListTagsForResourceInput{
+				resp, err := s.svc.ListTagsForResourceWithContext(ctx, &ssm.ListTagsForResourceInput{
 					ResourceType: aws.String(ssm.ResourceTypeForTaggingParameter),
 					ResourceId:   aws.String(name),
 				})
@@ -87,31 +251,15 @@ func (s *SSMStorage) Export(path string, decrypt bool) (interface{}, error) {
 					mx.Lock()
 					values[name] = value
 					mx.Unlock()
+					return
 				}
 
-				vType := func() string {
-					for _, tag := range resp.TagList {
-						if *tag.Key == "type" {
-							return aws.StringValue(tag.Value)
-						}
-					}
-
-					return "string"
-				}()
+				vType := typeTag(resp.TagList)
 
 				s.logger.WithField("name", name).Debugf("converting to %s", vType)
 
 				mx.Lock()
-				switch vType {
-				case "bool":
-					values[name], _ = strconv.ParseBool(value)
-				case "float64":
-					values[name], _ = strconv.ParseFloat(value, 64)
-				case "nil":
-					values[name] = nil
-				default:
-					values[name] = value
-				}
+				values[name] = typedValue(vType, value)
 				mx.Unlock()
 
 			}(aws.StringValue(p.Name), aws.StringValue(p.Value))
@@ -119,33 +267,38 @@ func (s *SSMStorage) Export(path string, decrypt bool) (interface{}, error) {
 
 		return !lastPage
 	})
-	if err != nil {
-		return nil, err
-	}
 
 	wg.Wait()
-	bar.Finish()
+
+	if err == nil {
+		err = pauseErr
+	}
+	if err == nil {
+		err = ctx.Err()
+	}
+	if err != nil {
+		return nil, nil, err
+	}
 
 	tree := make(map[string]interface{})
+	keys := make(map[string]bool)
 
 	for k, v := range values {
-		tree[strings.TrimPrefix(k, path)] = v
+		key := strings.TrimPrefix(strings.TrimPrefix(k, path), "/")
+		tree[key] = v
+		if secure[k] {
+			keys[key] = true
+		}
 	}
 
-	return s.unflattern(tree)
+	return tree, keys, nil
 }
 
-func (s *SSMStorage) unflattern(params map[string]interface{}) (interface{}, error) {
+func unflattern(params map[string]interface{}) (interface{}, error) {
 	var mergeMaps func(m1 interface{}, m2 interface{}) interface{}
 	mergeMaps = func(m1 interface{}, m2 interface{}) interface{} {
 
 		switch m2 := m2.(type) {
-		case string:
-			return m2
-		case float64:
-			return m2
-		case nil:
-			return m2
 		case []interface{}:
 			m1, _ := m1.([]interface{})
 			for i2, v2 := range m2 {
@@ -178,6 +331,8 @@ func (s *SSMStorage) unflattern(params map[string]interface{}) (interface{}, err
 					m1[k2] = v2
 				}
 			}
+		default:
+			return m2
 		}
 
 		return m1
@@ -209,44 +364,91 @@ func (s *SSMStorage) unflattern(params map[string]interface{}) (interface{}, err
 }
 
 func (s *SSMStorage) Delete(values map[string]interface{}) (int, error) {
+	progress, finish := progressBar()
+	defer finish()
+
+	return s.DeleteContext(context.Background(), values, DeleteOptions{Progress: progress})
+}
+
+// DeletePath deletes the parameters described under the path, names are
+// listed rather than rebuilt from an exported tree.
+func (s *SSMStorage) DeletePath(path string) (int, error) {
+	var names []string
+
+	s.logger.WithField("path", path).Debug("describe parameters by path")
+
+	err := s.svc.DescribeParametersPages(&ssm.DescribeParametersInput{
+		ParameterFilters: []*ssm.ParameterStringFilter{{
+			Key:    aws.String("Path"),
+			Option: aws.String("Recursive"),
+			Values: []*string{aws.String(path)},
+		}},
+	}, func(page *ssm.DescribeParametersOutput, lastPage bool) bool {
+		for _, m := range page.Parameters {
+			names = append(names, aws.StringValue(m.Name))
+		}
+
+		return !lastPage
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return s.deleteNames(names)
+}
+
+// DeleteContext stops deleting once the context is done, parameters that
+// have already been deleted are not restored and only they are counted.
+func (s *SSMStorage) DeleteContext(ctx context.Context, values map[string]interface{}, opts DeleteOptions) (int, error) {
 	var wg sync.WaitGroup
+	var mx sync.Mutex
 	var delParamError error
 	var i uint32
+	var deleted int64
 
 	total := len(values)
-	bar := pb.New(total)
-	bar.Output = os.Stderr
-	bar.Start()
+	t := &tracker{progress: opts.Progress}
+	t.add(total)
 
 	for k := range values {
-		wg.Add(1)
-
 		if i%20 == 0 && i > 0 {
-			s.logger.Debugf("sleep for a %d seconds", s.sleep)
-			time.Sleep(time.Duration(s.sleep) * time.Second)
+			// pause only fails once the context is done, which is reported
+			// after the goroutines have finished
+			if s.pause(ctx) != nil {
+				break
+			}
+		}
+
+		if ctx.Err() != nil {
+			break
 		}
 
 		i++
+		wg.Add(1)
 
 		go func(k string) {
 			defer func() {
-				bar.Increment()
+				t.increment()
 				wg.Done()
 			}()
 
 			k = fmt.Sprintf("/%s", k)
 			s.logger.WithField("name", k).Debug("deleting ssm parameter")
 
-			_, err := s.svc.DeleteParameter(&ssm.DeleteParameterInput{
+			_, err := s.svc.DeleteParameterWithContext(ctx, &ssm.DeleteParameterInput{
 				Name: aws.String(k),
 			})
 			if err != nil {
+				mx.Lock()
 				delParamError = err
+				mx.Unlock()
+			} else {
+				atomic.AddInt64(&deleted, 1)
 			}
 
 			s.logger.WithField("name", k).Debug("deleting metadata for ssm parameter")
 
-			s.svc.RemoveTagsFromResource(&ssm.RemoveTagsFromResourceInput{
+			s.svc.RemoveTagsFromResourceWithContext(ctx, &ssm.RemoveTagsFromResourceInput{
 				ResourceId: aws.String(k),
 			})
 
@@ -254,75 +456,183 @@ func (s *SSMStorage) Delete(values map[string]interface{}) (int, error) {
 	}
 
 	wg.Wait()
-	bar.Finish()
+
+	if err := ctx.Err(); err != nil {
+		return int(atomic.LoadInt64(&deleted)), err
+	}
 
 	return total, delParamError
 }
 
 func (s *SSMStorage) Import(values map[string]interface{}, msg string, encrypt bool) (int, error) {
+	progress, finish := progressBar()
+	defer finish()
+
+	return s.ImportContext(context.Background(), values, ImportOptions{Message: msg, Encrypt: encrypt, Progress: progress})
+}
+
+// ImportContext stops importing once the context is done, parameters that
+// have already been written are kept and only they are counted. Values are
+// linted and counted against the quota first, nothing is written when they
+// break SSM limits.
+func (s *SSMStorage) ImportContext(ctx context.Context, values map[string]interface{}, opts ImportOptions) (int, error) {
+	if problems := Lint(values); len(problems) > 0 {
+		return 0, &LintError{Problems: problems}
+	}
+
+	problems, err := s.Quota(ctx, values)
+	if ctx.Err() != nil {
+		return 0, ctx.Err()
+	}
+
+	if err != nil {
+		return 0, err
+	}
+
+	if len(problems) > 0 {
+		return 0, &LintError{Problems: problems}
+	}
+
 	var wg sync.WaitGroup
+	var mx sync.Mutex
 	var putParamError error
 	var i uint32
+	var written int64
 	var paramType string
 
-	if encrypt {
+	if opts.Encrypt {
 		paramType = ssm.ParameterTypeSecureString
 	} else {
 		paramType = ssm.ParameterTypeString
 	}
 
 	total := len(values)
-
-	bar := pb.StartNew(total)
-	bar.Output = os.Stderr
+	t := &tracker{progress: opts.Progress}
+	t.add(total)
 
 	for k, v := range values {
-		wg.Add(1)
-
 		if i%10 == 0 && i > 0 {
-			s.logger.Debugf("sleep for a %d seconds", s.sleep)
-			time.Sleep(time.Duration(s.sleep) * time.Second)
+			// pause only fails once the context is done, which is reported
+			// after the goroutines have finished
+			if s.pause(ctx) != nil {
+				break
+			}
+		}
+
+		if ctx.Err() != nil {
+			break
 		}
 
 		i++
+		wg.Add(1)
 
 		go func(k string, v interface{}) {
 			defer func() {
-				bar.Increment()
+				t.increment()
 				wg.Done()
 			}()
-			k = fmt.Sprintf("/%s", k)
-			s.logger.WithField("name", k).Debug("putting ssm parameter")
-
-			_, err := s.svc.PutParameter(&ssm.PutParameterInput{
-				Name:        aws.String(k),
-				Value:       aws.String(fmt.Sprint(v)),
-				Type:        aws.String(paramType),
-				Overwrite:   aws.Bool(true),
-				Description: aws.String(msg),
-			})
-			if err != nil {
-				putParamError = err
-				return
-			}
 
-			_, err = s.svc.AddTagsToResource(&ssm.AddTagsToResourceInput{
-				ResourceId:   aws.String(k),
-				ResourceType: aws.String(ssm.ResourceTypeForTaggingParameter),
-				Tags: []*ssm.Tag{&ssm.Tag{
-					Key:   aws.String("type"),
-					Value: aws.String(reflect.TypeOf(v).Kind().String()),
-				}},
-			})
-			if err != nil {
+			if err := s.put(ctx, k, v, valueType(v), paramType, opts.Message); err != nil {
+				mx.Lock()
 				putParamError = err
+				mx.Unlock()
+			} else {
+				atomic.AddInt64(&written, 1)
 			}
 
 		}(k, v)
 	}
 
 	wg.Wait()
-	bar.Finish()
+
+	if err := ctx.Err(); err != nil {
+		return int(atomic.LoadInt64(&written)), err
+	}
 
 	return total, putParamError
 }
+
+func (s *SSMStorage) put(ctx context.Context, k string, v interface{}, vType, paramType, msg string) error {
+	k = fmt.Sprintf("/%s", k)
+	s.logger.WithField("name", k).Debug("putting ssm parameter")
+
+	_, err := s.svc.PutParameterWithContext(ctx, &ssm.PutParameterInput{
+		Name:        aws.String(k),
+		Value:       aws.String(stringValue(v)),
+		Type:        aws.String(paramType),
+		Overwrite:   aws.Bool(true),
+		Description: aws.String(msg),
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = s.svc.AddTagsToResourceWithContext(ctx, &ssm.AddTagsToResourceInput{
+		ResourceId:   aws.String(k),
+		ResourceType: aws.String(ssm.ResourceTypeForTaggingParameter),
+		Tags: []*ssm.Tag{&ssm.Tag{
+			Key:   aws.String("type"),
+			Value: aws.String(vType),
+		}},
+	})
+
+	return err
+}
+
+// typeTag returns the value type kept in the type tag, parameters created
+// outside of json2ssm are strings.
+func typeTag(tags []*ssm.Tag) string {
+	for _, tag := range tags {
+		if aws.StringValue(tag.Key) == "type" {
+			return aws.StringValue(tag.Value)
+		}
+	}
+
+	return "string"
+}
+
+func valueType(v interface{}) string {
+	if v == nil {
+		return "nil"
+	}
+
+	if _, ok := v.(time.Time); ok {
+		return "datetime"
+	}
+
+	return reflect.TypeOf(v).Kind().String()
+}
+
+func stringValue(v interface{}) string {
+	if v == nil {
+		return "null"
+	}
+
+	if t, ok := v.(time.Time); ok {
+		return t.Format(time.RFC3339Nano)
+	}
+
+	return fmt.Sprint(v)
+}
+
+func typedValue(vType, value string) interface{} {
+	switch vType {
+	case "bool":
+		v, _ := strconv.ParseBool(value)
+		return v
+	case "float64":
+		v, _ := strconv.ParseFloat(value, 64)
+		return v
+	case "int64":
+		v, _ := strconv.ParseInt(value, 10, 64)
+		return v
+	case "datetime":
+		if v, err := time.Parse(time.RFC3339Nano, value); err == nil {
+			return v
+		}
+	case "nil":
+		return nil
+	}
+
+	return value
+}
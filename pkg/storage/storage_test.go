@@ -1,12 +1,17 @@
 package storage_test
 
 import (
+	"context"
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/service/ssm"
 	"github.com/aws/aws-sdk-go/service/ssm/ssmiface"
 	"github.com/b-b3rn4rd/json2ssm/mocks"
+	"github.com/b-b3rn4rd/json2ssm/pkg/fakessm"
 	"github.com/b-b3rn4rd/json2ssm/pkg/storage"
 	"github.com/sirupsen/logrus/hooks/test"
 	"github.com/stretchr/testify/assert"
@@ -19,11 +24,11 @@ type SSMMock struct {
 	listTagsForResourceOutput *ssm.ListTagsForResourceOutput
 }
 
-func (s *SSMMock) GetParametersByPathPages(input *ssm.GetParametersByPathInput, cb func(*ssm.GetParametersByPathOutput, bool) bool) error {
+func (s *SSMMock) GetParametersByPathPagesWithContext(ctx aws.Context, input *ssm.GetParametersByPathInput, cb func(*ssm.GetParametersByPathOutput, bool) bool, opts ...request.Option) error {
 	cb(s.output, true)
 	return nil
 }
-func (s *SSMMock) ListTagsForResource(input *ssm.ListTagsForResourceInput) (*ssm.ListTagsForResourceOutput, error) {
+func (s *SSMMock) ListTagsForResourceWithContext(ctx aws.Context, input *ssm.ListTagsForResourceInput, opts ...request.Option) (*ssm.ListTagsForResourceOutput, error) {
 	return s.listTagsForResourceOutput, nil
 }
 
@@ -82,12 +87,12 @@ func TestDelete(t *testing.T) {
 	deleteParameterExpectedOutput := &ssm.DeleteParameterOutput{}
 	removeTagsToResourceExpectedOutput := &ssm.RemoveTagsFromResourceOutput{}
 
-	s.On("DeleteParameter", mock.MatchedBy(func(input *ssm.DeleteParameterInput) bool {
+	s.On("DeleteParameterWithContext", mock.Anything, mock.MatchedBy(func(input *ssm.DeleteParameterInput) bool {
 		v := deleteParameterExpectedInput[aws.StringValue(input.Name)]
 		return assert.Equal(t, v, input)
 	})).Return(deleteParameterExpectedOutput, nil)
 
-	s.On("RemoveTagsFromResource", mock.MatchedBy(func(input *ssm.RemoveTagsFromResourceInput) bool {
+	s.On("RemoveTagsFromResourceWithContext", mock.Anything, mock.MatchedBy(func(input *ssm.RemoveTagsFromResourceInput) bool {
 		v := removeTagsToResourceExpectedInput[aws.StringValue(input.ResourceId)]
 		return assert.Equal(t, v, input)
 	})).Return(removeTagsToResourceExpectedOutput, nil)
@@ -96,8 +101,8 @@ func TestDelete(t *testing.T) {
 	str := storage.New(s, logger)
 	str.Delete(values)
 
-	s.AssertNumberOfCalls(t, "DeleteParameter", 6)
-	s.AssertNumberOfCalls(t, "RemoveTagsFromResource", 6)
+	s.AssertNumberOfCalls(t, "DeleteParameterWithContext", 6)
+	s.AssertNumberOfCalls(t, "RemoveTagsFromResourceWithContext", 6)
 }
 
 func TestExport(t *testing.T) {
@@ -255,20 +260,178 @@ func TestImport(t *testing.T) {
 	putParameterExpectedOutput := &ssm.PutParameterOutput{}
 	addTagsToResourceExpectedOutput := &ssm.AddTagsToResourceOutput{}
 
-	s.On("PutParameter", mock.MatchedBy(func(input *ssm.PutParameterInput) bool {
+	s.On("PutParameterWithContext", mock.Anything, mock.MatchedBy(func(input *ssm.PutParameterInput) bool {
 		v := putParameterExpectedInput[aws.StringValue(input.Name)]
 		return assert.Equal(t, v, input)
 	})).Return(putParameterExpectedOutput, nil)
 
-	s.On("AddTagsToResource", mock.MatchedBy(func(input *ssm.AddTagsToResourceInput) bool {
+	s.On("AddTagsToResourceWithContext", mock.Anything, mock.MatchedBy(func(input *ssm.AddTagsToResourceInput) bool {
 		v := addTagsToResourceExpectedInput[aws.StringValue(input.ResourceId)]
 		return assert.Equal(t, v, input)
 	})).Return(addTagsToResourceExpectedOutput, nil)
 
+	s.On("DescribeParametersPagesWithContext", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
 	logger, _ := test.NewNullLogger()
 	str := storage.New(s, logger)
 	str.Import(values, msg, false)
 
-	s.AssertNumberOfCalls(t, "PutParameter", 6)
-	s.AssertNumberOfCalls(t, "AddTagsToResource", 6)
+	s.AssertNumberOfCalls(t, "PutParameterWithContext", 6)
+	s.AssertNumberOfCalls(t, "AddTagsToResourceWithContext", 6)
+}
+
+func TestFlatten(t *testing.T) {
+	s := &SSMMock{}
+	s.output = &ssm.GetParametersByPathOutput{
+		Parameters: []*ssm.Parameter{
+			{
+				Name:  aws.String("/app/db/host"),
+				Type:  aws.String(ssm.ParameterTypeString),
+				Value: aws.String("localhost"),
+			},
+			{
+				Name:  aws.String("/app/db/password"),
+				Type:  aws.String(ssm.ParameterTypeSecureString),
+				Value: aws.String("secret"),
+			},
+		},
+	}
+	s.listTagsForResourceOutput = &ssm.ListTagsForResourceOutput{}
+
+	logger, _ := test.NewNullLogger()
+	str := storage.New(s, logger)
+	values, secure, err := str.Flatten("/app", true)
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"db/host": "localhost", "db/password": "secret"}, values)
+	assert.Equal(t, map[string]bool{"db/password": true}, secure)
+}
+
+func TestRoundTrip(t *testing.T) {
+	values := map[string]interface{}{
+		"app/name":        "bernard",
+		"app/code":        float64(3000),
+		"app/enabled":     true,
+		"app/manager":     nil,
+		"app/colors/0":    "red",
+		"app/colors/1":    "blue",
+		"app/address/zip": "3000",
+		"app/replicas":    int64(9007199254740993),
+		"app/released":    time.Date(1979, 5, 27, 7, 32, 0, 0, time.UTC),
+		"app/flags/0":     true,
+		"app/flags/1":     false,
+	}
+
+	logger, _ := test.NewNullLogger()
+	str := storage.New(fakessm.New(), logger)
+
+	total, err := str.Import(values, "", true)
+	assert.NoError(t, err)
+	assert.Equal(t, 11, total)
+
+	r, err := str.Export("/app", true)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"name":     "bernard",
+		"code":     float64(3000),
+		"enabled":  true,
+		"manager":  nil,
+		"colors":   []interface{}{"red", "blue"},
+		"address":  map[string]interface{}{"zip": "3000"},
+		"replicas": int64(9007199254740993),
+		"released": time.Date(1979, 5, 27, 7, 32, 0, 0, time.UTC),
+		"flags":    []interface{}{true, false},
+	}, r)
+
+	total, err = str.Delete(values)
+	assert.NoError(t, err)
+	assert.Equal(t, 11, total)
+
+	r, err = str.Export("/app", true)
+	assert.NoError(t, err)
+	assert.Nil(t, r)
+}
+
+func TestImportContextProgress(t *testing.T) {
+	values := map[string]interface{}{
+		"app/name": "bernard",
+		"app/code": float64(3000),
+	}
+
+	logger, _ := test.NewNullLogger()
+	str := storage.New(fakessm.New(), logger)
+
+	var calls [][2]int
+	total, err := str.ImportContext(context.Background(), values, storage.ImportOptions{
+		Progress: func(done, total int) {
+			calls = append(calls, [2]int{done, total})
+		},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, total)
+	assert.Equal(t, [][2]int{{0, 2}, {1, 2}, {2, 2}}, calls)
+
+	calls = nil
+	r, err := str.ExportContext(context.Background(), "/app", storage.ExportOptions{
+		Progress: func(done, total int) {
+			calls = append(calls, [2]int{done, total})
+		},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"name": "bernard", "code": float64(3000)}, r)
+	assert.Equal(t, [2]int{2, 2}, calls[len(calls)-1])
+}
+
+func TestContextCanceled(t *testing.T) {
+	values := map[string]interface{}{"app/name": "bernard"}
+	svc := fakessm.New()
+
+	logger, _ := test.NewNullLogger()
+	str := storage.New(svc, logger)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := str.ImportContext(ctx, values, storage.ImportOptions{})
+	assert.Equal(t, context.Canceled, err)
+
+	_, err = svc.GetParameter(&ssm.GetParameterInput{Name: aws.String("/app/name")})
+	assert.Error(t, err)
+
+	_, err = str.ExportContext(ctx, "/app", storage.ExportOptions{})
+	assert.Error(t, err)
+
+	_, err = str.DeleteContext(ctx, values, storage.DeleteOptions{})
+	assert.Equal(t, context.Canceled, err)
+}
+
+func TestContextCanceledCount(t *testing.T) {
+	values := map[string]interface{}{}
+	for i := 0; i < 25; i++ {
+		values[fmt.Sprintf("app/key%d", i)] = "v"
+	}
+
+	svc := fakessm.New()
+	logger, _ := test.NewNullLogger()
+	str := storage.New(svc, logger)
+
+	// the deadline passes while pausing after the first batch
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	total, err := str.ImportContext(ctx, values, storage.ImportOptions{})
+	assert.Equal(t, context.DeadlineExceeded, err)
+	assert.Equal(t, 10, total)
+
+	for k := range values {
+		_, err := svc.PutParameter(&ssm.PutParameterInput{Name: aws.String("/" + k), Value: aws.String("v"), Type: aws.String(ssm.ParameterTypeString), Overwrite: aws.Bool(true)})
+		assert.NoError(t, err)
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	total, err = str.DeleteContext(ctx, values, storage.DeleteOptions{})
+	assert.Equal(t, context.DeadlineExceeded, err)
+	assert.Equal(t, 20, total)
 }
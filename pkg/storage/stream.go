@@ -0,0 +1,142 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/b-b3rn4rd/json2ssm/pkg/source"
+)
+
+// streamBatch is the number of parameters written at a time by ImportStream.
+const streamBatch = 10
+
+// ImportStream writes records as stream sends them, at most streamBatch
+// values are held at a time and only names are kept to count them against the
+// quota. Every record is linted before it is written, unlike ImportContext parameters
+// received before a problem has been found are already written. The stream
+// must close records when it returns, the records it has sent are not written
+// when it fails, e.g. on a malformed document, and it is canceled when
+// ImportStream returns early.
+func (s *SSMStorage) ImportStream(ctx context.Context, stream func(context.Context, chan<- source.Record) error, opts ImportOptions) (int, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	records := make(chan source.Record)
+	done := make(chan struct{})
+	var streamErr error
+
+	go func() {
+		streamErr = stream(ctx, records)
+		close(done)
+	}()
+
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	paramType := ssm.ParameterTypeString
+	if opts.Encrypt {
+		paramType = ssm.ParameterTypeSecureString
+	}
+
+	stored, err := s.storedNames(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	t := &tracker{progress: opts.Progress}
+	written := 0
+	batch := make([]source.Record, 0, streamBatch)
+
+	flush := func() error {
+		var wg sync.WaitGroup
+		var mx sync.Mutex
+		var putParamError error
+
+		for _, rec := range batch {
+			wg.Add(1)
+
+			go func(rec source.Record) {
+				defer func() {
+					t.increment()
+					wg.Done()
+				}()
+
+				vType := rec.Type
+				if vType == "" {
+					vType = valueType(rec.Value)
+				}
+
+				err := s.put(ctx, rec.Key, rec.Value, vType, paramType, opts.Message)
+
+				mx.Lock()
+				if err != nil {
+					putParamError = err
+				} else {
+					written++
+				}
+				mx.Unlock()
+			}(rec)
+		}
+
+		wg.Wait()
+		batch = batch[:0]
+
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		return putParamError
+	}
+
+	for {
+		var rec source.Record
+		var ok bool
+
+		select {
+		case <-ctx.Done():
+			return written, ctx.Err()
+		case rec, ok = <-records:
+		}
+
+		if !ok {
+			<-done
+			if streamErr != nil {
+				return written, streamErr
+			}
+
+			break
+		}
+
+		if problems := Lint(map[string]interface{}{rec.Key: rec.Value}); len(problems) > 0 {
+			return written, &LintError{Problems: problems}
+		}
+
+		if name := fmt.Sprintf("/%s", rec.Key); !stored[name] {
+			stored[name] = true
+			if len(stored) > maxStandardParams {
+				return written, &LintError{Problems: quotaProblem(len(stored))}
+			}
+		}
+
+		if len(batch) == 0 && written > 0 {
+			if err := s.pause(ctx); err != nil {
+				return written, err
+			}
+		}
+
+		t.add(1)
+		batch = append(batch, rec)
+
+		if len(batch) == streamBatch {
+			if err := flush(); err != nil {
+				return written, err
+			}
+		}
+	}
+
+	err = flush()
+
+	return written, err
+}
@@ -0,0 +1,85 @@
+package storage_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/b-b3rn4rd/json2ssm/pkg/fakessm"
+	"github.com/b-b3rn4rd/json2ssm/pkg/source"
+	"github.com/b-b3rn4rd/json2ssm/pkg/storage"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImportStream(t *testing.T) {
+	logger, _ := test.NewNullLogger()
+	str := storage.New(fakessm.New(), logger)
+
+	stream := func(ctx context.Context, records chan<- source.Record) error {
+		defer close(records)
+		for i := 0; i < 9; i++ {
+			records <- source.Record{Key: fmt.Sprintf("app/hosts/%d", i), Value: fmt.Sprintf("host%d", i), Type: "string"}
+		}
+		records <- source.Record{Key: "app/port", Value: float64(80), Type: "float64"}
+		return nil
+	}
+
+	var done int
+	total, err := str.ImportStream(context.Background(), stream, storage.ImportOptions{Progress: func(d, _ int) { done = d }})
+	assert.NoError(t, err)
+	assert.Equal(t, 10, total)
+	assert.Equal(t, 10, done)
+
+	r, err := str.Export("/app", false)
+	assert.NoError(t, err)
+	assert.Equal(t, float64(80), r.(map[string]interface{})["port"])
+	assert.Len(t, r.(map[string]interface{})["hosts"], 9)
+}
+
+func streamJSON(doc string) func(context.Context, chan<- source.Record) error {
+	return func(ctx context.Context, records chan<- source.Record) error {
+		return source.Stream(ctx, strings.NewReader(doc), records)
+	}
+}
+
+func TestImportStreamLints(t *testing.T) {
+	logger, _ := test.NewNullLogger()
+	str := storage.New(fakessm.New(), logger)
+
+	total, err := str.ImportStream(context.Background(), streamJSON(`{"app": {"name": "x", "bad key": "y", "other": "z"}}`), storage.ImportOptions{})
+
+	assert.Equal(t, 0, total)
+	assert.EqualError(t, err, "1 problems found: /app/bad key: name can only contain a-z, A-Z, 0-9, _, ., - and /")
+
+	r, err := str.Export("/app", false)
+	assert.NoError(t, err)
+	assert.Nil(t, r)
+}
+
+func TestImportStreamMalformed(t *testing.T) {
+	logger, _ := test.NewNullLogger()
+	str := storage.New(fakessm.New(), logger)
+
+	total, err := str.ImportStream(context.Background(), streamJSON(`{"app": {"name": "x", "port": 80} "other"}`), storage.ImportOptions{})
+
+	assert.Equal(t, 0, total)
+	assert.Error(t, err)
+
+	r, err := str.Export("/app", false)
+	assert.NoError(t, err)
+	assert.Nil(t, r)
+}
+
+func TestImportStreamCountsWrites(t *testing.T) {
+	svc := fakessm.New()
+	svc.ThrottleEvery = 3
+	logger, _ := test.NewNullLogger()
+	str := storage.New(svc, logger)
+
+	total, err := str.ImportStream(context.Background(), streamJSON(`{"a": 1, "b": 2, "c": 3, "d": 4}`), storage.ImportOptions{})
+
+	assert.Error(t, err)
+	assert.True(t, total < 4, "written %d", total)
+}
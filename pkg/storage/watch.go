@@ -0,0 +1,88 @@
+package storage
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ssm"
+)
+
+// Fingerprint returns a digest of names and versions of parameters under the
+// given path, it changes whenever a parameter is added, updated or deleted.
+// Tags are not part of it, changing them doesn't create a new version.
+func (s *SSMStorage) Fingerprint(path string) (string, error) {
+	var versions []string
+
+	err := s.svc.DescribeParametersPages(&ssm.DescribeParametersInput{
+		ParameterFilters: []*ssm.ParameterStringFilter{{
+			Key:    aws.String("Path"),
+			Option: aws.String("Recursive"),
+			Values: []*string{aws.String(path)},
+		}},
+	}, func(page *ssm.DescribeParametersOutput, lastPage bool) bool {
+		for _, m := range page.Parameters {
+			versions = append(versions, fmt.Sprintf("%s:%d:%d",
+				aws.StringValue(m.Name), aws.Int64Value(m.Version), aws.TimeValue(m.LastModifiedDate).UnixNano()))
+		}
+
+		return !lastPage
+	})
+	if err != nil {
+		return "", err
+	}
+
+	sort.Strings(versions)
+
+	h := sha256.New()
+	for _, v := range versions {
+		fmt.Fprintln(h, v)
+	}
+
+	return fmt.Sprintf("%x", h.Sum(nil)), nil
+}
+
+// Watch exports the given path and passes the tree to cb, then polls the
+// path every interval and exports it again only when its fingerprint changes.
+// Watch returns when stop is closed or cb returns an error.
+func (s *SSMStorage) Watch(path string, decrypt bool, interval time.Duration, stop <-chan struct{}, cb func(interface{}) error) error {
+	var last string
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		fingerprint, err := s.Fingerprint(path)
+		if err != nil {
+			s.logger.WithField("path", path).WithError(err).Warn("can't describe parameters, retrying")
+		} else if fingerprint != last {
+			s.logger.WithField("path", path).Debug("parameters changed, exporting")
+
+			tree, err := s.Export(path, decrypt)
+			if err != nil {
+				s.logger.WithField("path", path).WithError(err).Warn("can't export parameters, retrying")
+			} else {
+				if err = cb(tree); err != nil {
+					return err
+				}
+
+				last = fingerprint
+			}
+		}
+
+		// stop takes priority over a tick that is already due
+		select {
+		case <-stop:
+			return nil
+		default:
+		}
+
+		select {
+		case <-stop:
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
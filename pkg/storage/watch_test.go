@@ -0,0 +1,59 @@
+package storage_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/b-b3rn4rd/json2ssm/mocks"
+	"github.com/b-b3rn4rd/json2ssm/pkg/storage"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestWatch(t *testing.T) {
+	s := &mocks.SSMAPI{}
+	versions := []int64{1, 1, 2}
+	calls := 0
+
+	s.On("DescribeParametersPages", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		cb := args.Get(1).(func(*ssm.DescribeParametersOutput, bool) bool)
+		cb(&ssm.DescribeParametersOutput{Parameters: []*ssm.ParameterMetadata{
+			{Name: aws.String("/app/name"), Version: aws.Int64(versions[calls])},
+		}}, true)
+		calls++
+	}).Return(nil)
+
+	s.On("GetParametersByPathPagesWithContext", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		cb := args.Get(2).(func(*ssm.GetParametersByPathOutput, bool) bool)
+		cb(&ssm.GetParametersByPathOutput{Parameters: []*ssm.Parameter{
+			{Name: aws.String("/app/name"), Type: aws.String(ssm.ParameterTypeString), Value: aws.String("bernard")},
+		}}, true)
+	}).Return(nil)
+
+	s.On("ListTagsForResourceWithContext", mock.Anything, mock.Anything).Return(&ssm.ListTagsForResourceOutput{}, nil)
+
+	logger, _ := test.NewNullLogger()
+	str := storage.New(s, logger)
+
+	stop := make(chan struct{})
+	var trees []interface{}
+
+	err := str.Watch("/app", false, time.Millisecond, stop, func(tree interface{}) error {
+		trees = append(trees, tree)
+		if len(trees) == 2 {
+			close(stop)
+		}
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"name": "bernard"},
+		map[string]interface{}{"name": "bernard"},
+	}, trees)
+	s.AssertNumberOfCalls(t, "DescribeParametersPages", 3)
+	s.AssertNumberOfCalls(t, "GetParametersByPathPagesWithContext", 2)
+}